@@ -0,0 +1,72 @@
+package kubernetes
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	archon "kubeup.com/archon/pkg/clientset"
+)
+
+func dataSourceArchonInstance() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceArchonInstanceRead,
+
+		Schema: map[string]*schema.Schema{
+			"metadata": namespacedMetadataSchema("instance", false),
+			"spec": {
+				Type:        schema.TypeList,
+				Description: "Archon Instance spec",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: instanceSpecFields(),
+				},
+			},
+			"status": {
+				Type:        schema.TypeList,
+				Description: "Archon Instance status",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: instanceStatusFields(),
+				},
+			},
+		},
+	}
+}
+
+func dataSourceArchonInstanceRead(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*archon.Clientset)
+
+	metadata := expandMetadata(d.Get("metadata").([]interface{}))
+	if metadata.Name == "" {
+		return fmt.Errorf("metadata.0.name is required to look up an instance")
+	}
+
+	log.Printf("[INFO] Reading instance %s", metadata.Name)
+	instance, err := conn.Archon().Instances(metadata.Namespace).Get(metadata.Name)
+	if err != nil {
+		log.Printf("[DEBUG] Received error: %#v", err)
+		return err
+	}
+	log.Printf("[INFO] Received instance: %#v", instance)
+	d.SetId(buildId(instance.ObjectMeta))
+
+	err = d.Set("metadata", flattenMetadata(instance.ObjectMeta))
+	if err != nil {
+		return err
+	}
+
+	flattened := flattenInstanceSpec(instance.Spec)
+	log.Printf("[DEBUG] Flattened instance spec: %#v", flattened)
+	err = d.Set("spec", flattened)
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("status", flattenInstanceStatus(instance.Status))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
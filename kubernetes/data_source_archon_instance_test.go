@@ -0,0 +1,74 @@
+package kubernetes
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccArchonDataSourceInstance_basic(t *testing.T) {
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckArchonDestroy("archon_instance", testAccArchonInstanceClient),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccArchonDataSourceInstanceConfig_basic(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.archon_instance.test", "metadata.0.name", name),
+					resource.TestCheckResourceAttr("data.archon_instance.test", "metadata.0.namespace", "default"),
+					resource.TestCheckResourceAttr("data.archon_instance.test", "metadata.0.labels.%", "1"),
+					resource.TestCheckResourceAttr("data.archon_instance.test", "metadata.0.labels.TestLabelOne", "one"),
+					resource.TestCheckResourceAttrSet("data.archon_instance.test", "metadata.0.uid"),
+					resource.TestCheckResourceAttr("data.archon_instance.test", "spec.#", "1"),
+					resource.TestCheckResourceAttr("data.archon_instance.test", "spec.0.image", "first"),
+					resource.TestCheckResourceAttr("data.archon_instance.test", "spec.0.os", "second"),
+					resource.TestCheckResourceAttr("data.archon_instance.test", "spec.0.network_name", "tf-acc-network"),
+					resource.TestCheckResourceAttr("data.archon_instance.test", "status.#", "1"),
+					resource.TestCheckResourceAttr("data.archon_instance.test", "status.0.phase", "Running"),
+					resource.TestCheckResourceAttrPair("data.archon_instance.test", "status.0.private_ip",
+						"archon_instance.test", "status.0.private_ip"),
+				),
+			},
+		},
+	})
+}
+
+func testAccArchonDataSourceInstanceConfig_basic(name string) string {
+	return fmt.Sprintf(`
+resource "archon_instance" "test" {
+	metadata {
+		labels {
+			TestLabelOne = "one"
+		}
+		name = "%s"
+	}
+	spec {
+		image = "first"
+		os = "second"
+		network_name = "${archon_network.test.metadata.0.name}"
+	}
+}
+
+resource "archon_network" "test" {
+	metadata {
+		name = "tf-acc-network"
+	}
+	spec {
+		region = "first"
+		zone = "second"
+		subnet = "10.0.0.0/24"
+	}
+}
+
+data "archon_instance" "test" {
+	metadata {
+		name = "${archon_instance.test.metadata.0.name}"
+		namespace = "${archon_instance.test.metadata.0.namespace}"
+	}
+}`, name)
+}
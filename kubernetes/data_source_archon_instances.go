@@ -0,0 +1,80 @@
+package kubernetes
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	archon "kubeup.com/archon/pkg/clientset"
+	"kubeup.com/archon/pkg/cluster"
+)
+
+func dataSourceArchonInstances() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceArchonInstancesRead,
+
+		Schema: map[string]*schema.Schema{
+			"namespace": {
+				Type:        schema.TypeString,
+				Description: "Namespace to look up instances in.",
+				Optional:    true,
+				Default:     "default",
+			},
+			"selector": {
+				Type:        schema.TypeList,
+				Description: "A label query over instances to return.",
+				Optional:    true,
+				MaxItems:    1,
+				Elem:        labelSelectorSchema(),
+			},
+			"phase": {
+				Type:        schema.TypeString,
+				Description: "Only return instances in this phase.",
+				Optional:    true,
+				ValidateFunc: validateAttributeValueIsIn([]string{
+					string(cluster.InstancePending),
+					string(cluster.InstanceInitializing),
+					string(cluster.InstanceRunning),
+					string(cluster.InstanceFailed),
+					string(cluster.InstanceUnknown),
+				}),
+			},
+			"instances": {
+				Type:        schema.TypeList,
+				Description: "Matching instances, sorted by name.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: instanceSummaryFields(),
+				},
+			},
+		},
+	}
+}
+
+func dataSourceArchonInstancesRead(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*archon.Clientset)
+
+	namespace := d.Get("namespace").(string)
+	selector := expandLabelSelector(d.Get("selector").([]interface{}))
+	phase := cluster.InstancePhase(d.Get("phase").(string))
+
+	log.Printf("[INFO] Listing instances in %s", namespace)
+	instances, err := listInstancesBySelector(conn, namespace, selector, phase)
+	if err != nil {
+		log.Printf("[DEBUG] Received error: %#v", err)
+		return err
+	}
+	log.Printf("[INFO] Received %d instances", len(instances))
+
+	query := fmt.Sprintf("%s/%s", metav1.FormatLabelSelector(selector), phase)
+	d.SetId(fmt.Sprintf("%s/%d", namespace, hashcode.String(query)))
+
+	err = d.Set("instances", flattenInstanceSummaries(instances))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package kubernetes
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccArchonDataSourceInstances_basic(t *testing.T) {
+	prefix := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckArchonDestroy("archon_instance", testAccArchonInstanceClient),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccArchonDataSourceInstancesConfig_basic(prefix),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.archon_instances.test", "instances.#", "2"),
+					resource.TestCheckResourceAttr("data.archon_instances.test", "instances.0.name", prefix+"-a"),
+					resource.TestCheckResourceAttr("data.archon_instances.test", "instances.0.namespace", "default"),
+					resource.TestCheckResourceAttr("data.archon_instances.test", "instances.0.phase", "Running"),
+					resource.TestCheckResourceAttrSet("data.archon_instances.test", "instances.0.instance_id"),
+					resource.TestCheckResourceAttrSet("data.archon_instances.test", "instances.0.private_ip"),
+					resource.TestCheckResourceAttr("data.archon_instances.test", "instances.1.name", prefix+"-b"),
+					resource.TestCheckResourceAttr("data.archon_instances.none", "instances.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccArchonDataSourceInstancesConfig_basic(prefix string) string {
+	return fmt.Sprintf(`
+resource "archon_instance" "b" {
+	metadata {
+		labels {
+			app = "%[1]s"
+		}
+		name = "%[1]s-b"
+	}
+	spec {
+		image = "first"
+		os = "second"
+		network_name = "${archon_network.test.metadata.0.name}"
+	}
+}
+
+resource "archon_instance" "a" {
+	metadata {
+		labels {
+			app = "%[1]s"
+		}
+		name = "%[1]s-a"
+	}
+	spec {
+		image = "first"
+		os = "second"
+		network_name = "${archon_network.test.metadata.0.name}"
+	}
+}
+
+resource "archon_network" "test" {
+	metadata {
+		name = "tf-acc-network"
+	}
+	spec {
+		region = "first"
+		zone = "second"
+		subnet = "10.0.0.0/24"
+	}
+}
+
+data "archon_instances" "test" {
+	selector {
+		match_labels {
+			app = "%[1]s"
+		}
+	}
+	phase = "Running"
+
+	depends_on = ["archon_instance.a", "archon_instance.b"]
+}
+
+data "archon_instances" "none" {
+	selector {
+		match_expressions {
+			key = "app"
+			operator = "In"
+			values = ["%[1]s-missing"]
+		}
+	}
+
+	depends_on = ["archon_instance.a", "archon_instance.b"]
+}`, prefix)
+}
@@ -65,3 +65,29 @@ func stringifyEvents(events []api.Event) string {
 	}
 	return output
 }
+
+// uniqueEvents drops events repeating the message of an earlier one,
+// e.g. the same quota error reported for several instances
+func uniqueEvents(events []api.Event) []api.Event {
+	var unique []api.Event
+	seen := make(map[string]bool, 0)
+	for _, e := range events {
+		if seen[e.Message] {
+			continue
+		}
+		unique = append(unique, e)
+		seen[e.Message] = true
+	}
+	return unique
+}
+
+// stringifyObjectEvents is like stringifyEvents, but names the object
+// each event is about, for events collected from several objects
+func stringifyObjectEvents(events []api.Event) string {
+	var output string
+	for _, e := range events {
+		output += fmt.Sprintf("\n   * %s %s: %s: %s",
+			e.InvolvedObject.Kind, e.InvolvedObject.Name, e.Reason, e.Message)
+	}
+	return output
+}
@@ -0,0 +1,165 @@
+package kubernetes
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"kubeup.com/archon/pkg/cluster"
+)
+
+// Files with content_encoding = "auto" are sent gzipped and base64 encoded,
+// while the state only keeps a hash of the plaintext
+const (
+	autoFileEncoding      = "gzip+base64"
+	fileContentHashPrefix = "sha256:"
+)
+
+func encodeFileContent(plaintext string) string {
+	var buf bytes.Buffer
+	// Writing to a bytes.Buffer doesn't fail
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(plaintext))
+	w.Close()
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func decodeFileContent(encoded string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	plaintext, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func fileContentHash(plaintext string) string {
+	return fmt.Sprintf("%s%x", fileContentHashPrefix, sha256.Sum256([]byte(plaintext)))
+}
+
+func isFileContentHash(content string) bool {
+	return strings.HasPrefix(content, fileContentHashPrefix)
+}
+
+// suppressFileContentHash hides the diff between the hash kept in state
+// for auto encoded files and the plaintext in the config, as long as the
+// file is still auto encoded
+func suppressFileContentHash(k, old, new string, d *schema.ResourceData) bool {
+	if !isFileContentHash(old) || old != fileContentHash(new) {
+		return false
+	}
+	encodingKey := strings.TrimSuffix(k, "content") + "content_encoding"
+	return d.Get(encodingKey).(string) == "auto"
+}
+
+// flattenAutoEncodedFiles replaces the content of files which were auto
+// encoded according to the prior state of the spec with the hash of their
+// plaintext, so it can be compared with the config
+func flattenAutoEncodedFiles(spec, prior []interface{}) error {
+	if len(spec) == 0 || spec[0] == nil || len(prior) == 0 || prior[0] == nil {
+		return nil
+	}
+	files, _ := spec[0].(map[string]interface{})["files"].([]interface{})
+	priorFiles, _ := prior[0].(map[string]interface{})["files"].([]interface{})
+
+	for i, f := range files {
+		if i >= len(priorFiles) || priorFiles[i] == nil {
+			break
+		}
+		if priorFiles[i].(map[string]interface{})["content_encoding"] != "auto" {
+			continue
+		}
+		file := f.(map[string]interface{})
+		if file["encoding"] != autoFileEncoding {
+			continue
+		}
+		plaintext, err := decodeFileContent(file["content"].(string))
+		if err != nil {
+			return fmt.Errorf("files.%d: failed to decode content: %s", i, err)
+		}
+		file["content"] = fileContentHash(plaintext)
+		file["content_encoding"] = "auto"
+		delete(file, "encoding")
+	}
+	return nil
+}
+
+// restoreFileContents puts back the encoded content of auto encoded files
+// which are unchanged, and therefore only known by their hash, from the
+// files currently stored in Archon
+func restoreFileContents(files, current []cluster.FileSpec) error {
+	for i := range files {
+		if files[i].Encoding != autoFileEncoding || !isFileContentHash(files[i].Content) {
+			continue
+		}
+		if i < len(current) && current[i].Encoding == autoFileEncoding {
+			plaintext, err := decodeFileContent(current[i].Content)
+			if err == nil && fileContentHash(plaintext) == files[i].Content {
+				files[i].Content = current[i].Content
+				continue
+			}
+		}
+		return fmt.Errorf("files.%d: content with hash %s is no longer available, please change the file to update it", i, files[i].Content)
+	}
+	return nil
+}
+
+// suppressConfigFileHash hides the diff between the hash kept in state for
+// a data_from_file entry and its path in the config while the file contents
+// are unchanged
+func suppressConfigFileHash(k, old, new string, d *schema.ResourceData) bool {
+	if !isFileContentHash(old) {
+		return false
+	}
+	content, err := readConfigFile(new)
+	if err != nil {
+		return false
+	}
+	return old == fileContentHash(content)
+}
+
+// restoreConfigFiles puts back the data of data_from_file entries which are
+// unchanged, and therefore only known by their hash, from the configs
+// currently stored in Archon
+func restoreConfigFiles(raw []interface{}, configs, current []cluster.ConfigSpec) error {
+	for i, c := range raw {
+		if i >= len(configs) || c == nil {
+			break
+		}
+		paths, _ := c.(map[string]interface{})["data_from_file"].(map[string]interface{})
+		for k, v := range paths {
+			hash := v.(string)
+			if !isFileContentHash(hash) {
+				continue
+			}
+			restored := false
+			for _, cur := range current {
+				if cur.Name != configs[i].Name {
+					continue
+				}
+				if content, ok := cur.Data[k]; ok && fileContentHash(content) == hash {
+					configs[i].Data[k] = content
+					restored = true
+				}
+				break
+			}
+			if !restored {
+				return fmt.Errorf("configs.%d.data_from_file.%s: contents with hash %s are no longer available, please change the file to update it", i, k, hash)
+			}
+		}
+	}
+	return nil
+}
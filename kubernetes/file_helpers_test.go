@@ -0,0 +1,195 @@
+package kubernetes
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"kubeup.com/archon/pkg/cluster"
+)
+
+func TestFileContentRoundTrip(t *testing.T) {
+	testCases := []string{
+		"",
+		"hello",
+		"#!/bin/sh\necho \"\x00\xff\"\n",
+	}
+	for i, plaintext := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			decoded, err := decodeFileContent(encodeFileContent(plaintext))
+			if err != nil {
+				t.Fatalf("Failed to decode content: %s", err)
+			}
+			if decoded != plaintext {
+				t.Fatalf("Expected %q, got %q", plaintext, decoded)
+			}
+		})
+	}
+}
+
+func TestAutoEncodedFiles(t *testing.T) {
+	config := []interface{}{
+		map[string]interface{}{
+			"os": "CoreOS",
+			"files": []interface{}{
+				map[string]interface{}{
+					"name":             "/etc/motd",
+					"content":          "hello",
+					"content_encoding": "auto",
+				},
+				map[string]interface{}{
+					"name":    "/etc/issue",
+					"content": "plain",
+				},
+			},
+		},
+	}
+
+	spec, err := expandInstanceSpec(config)
+	if err != nil {
+		t.Fatalf("Failed to expand spec: %s", err)
+	}
+	if spec.Files[0].Encoding != autoFileEncoding {
+		t.Fatalf("Expected encoding %q, got %q", autoFileEncoding, spec.Files[0].Encoding)
+	}
+	if spec.Files[0].Content == "hello" {
+		t.Fatalf("Expected content to be encoded")
+	}
+	if spec.Files[1].Encoding != "" || spec.Files[1].Content != "plain" {
+		t.Fatalf("Expected plain file to be unchanged, got %#v", spec.Files[1])
+	}
+
+	flattened := flattenInstanceSpec(spec)
+	err = flattenAutoEncodedFiles(flattened, config)
+	if err != nil {
+		t.Fatalf("Failed to flatten files: %s", err)
+	}
+	files := flattened[0].(map[string]interface{})["files"].([]interface{})
+	auto := files[0].(map[string]interface{})
+	if auto["content"] != fileContentHash("hello") {
+		t.Fatalf("Expected content hash %q, got %q", fileContentHash("hello"), auto["content"])
+	}
+	if auto["content_encoding"] != "auto" {
+		t.Fatalf("Expected content_encoding to be auto, got %q", auto["content_encoding"])
+	}
+	if _, ok := auto["encoding"]; ok {
+		t.Fatalf("Expected encoding to be removed, got %q", auto["encoding"])
+	}
+	if files[1].(map[string]interface{})["content"] != "plain" {
+		t.Fatalf("Expected plain file to be unchanged, got %#v", files[1])
+	}
+
+	// Expanding the state again keeps the hash, which is resolved against
+	// the files stored in Archon
+	restored, err := expandInstanceSpec(flattened)
+	if err != nil {
+		t.Fatalf("Failed to expand spec: %s", err)
+	}
+	if restored.Files[0].Content != fileContentHash("hello") {
+		t.Fatalf("Expected hash to be kept, got %q", restored.Files[0].Content)
+	}
+	err = restoreFileContents(restored.Files, spec.Files)
+	if err != nil {
+		t.Fatalf("Failed to restore files: %s", err)
+	}
+	if restored.Files[0].Content != spec.Files[0].Content {
+		t.Fatalf("Expected content %q, got %q", spec.Files[0].Content, restored.Files[0].Content)
+	}
+}
+
+func TestRestoreFileContentsMissing(t *testing.T) {
+	files := []cluster.FileSpec{
+		{Name: "/etc/motd", Encoding: autoFileEncoding, Content: fileContentHash("hello")},
+	}
+	current := []cluster.FileSpec{
+		{Name: "/etc/motd", Encoding: autoFileEncoding, Content: encodeFileContent("changed")},
+	}
+	if err := restoreFileContents(files, current); err == nil {
+		t.Fatal("Expected an error when the content doesn't match")
+	}
+	if err := restoreFileContents(files, nil); err == nil {
+		t.Fatal("Expected an error when the file is missing")
+	}
+}
+
+func TestSuppressFileContentHash(t *testing.T) {
+	r := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"spec": {
+				Type:     schema.TypeList,
+				Required: true,
+				MaxItems: 1,
+				Elem:     &schema.Resource{Schema: instanceSpecFields()},
+			},
+		},
+	}
+	state := &terraform.InstanceState{
+		ID: "test",
+		Attributes: map[string]string{
+			"spec.#":                          "1",
+			"spec.0.os":                       "CoreOS",
+			"spec.0.network_name":             "net",
+			"spec.0.files.#":                  "1",
+			"spec.0.files.0.name":             "motd",
+			"spec.0.files.0.content":          fileContentHash("hello"),
+			"spec.0.files.0.content_encoding": "auto",
+		},
+	}
+	file := func(content, contentEncoding string) map[string]interface{} {
+		f := map[string]interface{}{
+			"name":    "motd",
+			"content": content,
+		}
+		if contentEncoding != "" {
+			f["content_encoding"] = contentEncoding
+		}
+		return f
+	}
+
+	testCases := []struct {
+		File     map[string]interface{}
+		Expected string
+	}{
+		// Unchanged auto file
+		{file("hello", "auto"), ""},
+		// Changed auto file
+		{file("world", "auto"), "world"},
+		// Same plaintext, switched back to plain
+		{file("hello", ""), "hello"},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			c, err := config.NewRawConfig(map[string]interface{}{
+				"spec": []interface{}{
+					map[string]interface{}{
+						"os":           "CoreOS",
+						"network_name": "net",
+						"files":        []interface{}{tc.File},
+					},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			diff, err := r.Diff(state, terraform.NewResourceConfig(c))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var attr *terraform.ResourceAttrDiff
+			if diff != nil {
+				attr = diff.Attributes["spec.0.files.0.content"]
+			}
+			if tc.Expected == "" {
+				if attr != nil {
+					t.Fatalf("Expected no content diff, got %#v", attr)
+				}
+				return
+			}
+			if attr == nil || attr.New != tc.Expected {
+				t.Fatalf("Expected content diff to %q, got %#v", tc.Expected, attr)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package kubernetes
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/kubernetes/pkg/api"
+	archon "kubeup.com/archon/pkg/clientset"
+	"kubeup.com/archon/pkg/cluster"
+)
+
+// listInstancesBySelector returns instances in the namespace matching
+// the given label selector and, if not empty, the phase, sorted by name
+func listInstancesBySelector(conn *archon.Clientset, namespace string, selector *metav1.LabelSelector, phase cluster.InstancePhase) ([]cluster.Instance, error) {
+	sel, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] Listing instances in %q via this selector: %q", namespace, sel.String())
+	out, err := conn.Archon().Instances(namespace).List(metav1.ListOptions{
+		LabelSelector: sel.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The archon client doesn't pass list options to the server,
+	// so the selector has to be applied here as well
+	return filterInstances(out.Items, sel, phase), nil
+}
+
+// filterInstances keeps instances matching the selector and, if not empty,
+// the phase. The result is sorted by name.
+func filterInstances(in []cluster.Instance, sel labels.Selector, phase cluster.InstancePhase) []cluster.Instance {
+	instances := make([]cluster.Instance, 0, len(in))
+	for _, i := range in {
+		if !sel.Matches(labels.Set(i.Labels)) {
+			continue
+		}
+		if phase != "" && i.Status.Phase != phase {
+			continue
+		}
+		instances = append(instances, i)
+	}
+
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].Name < instances[j].Name
+	})
+
+	return instances
+}
+
+// instanceGroupSelector returns the selector used to find members of the
+// instance group. Like a ReplicaSet, a group without a selector owns
+// instances carrying its template labels.
+func instanceGroupSelector(ig *cluster.InstanceGroup) *metav1.LabelSelector {
+	sel := ig.Spec.Selector
+	if sel == nil || (len(sel.MatchLabels) == 0 && len(sel.MatchExpressions) == 0) {
+		return metav1.SetAsLabelSelector(labels.Set(ig.Spec.Template.Labels))
+	}
+	return sel
+}
+
+// instanceGroupOwner returns the name of the instance group controlling
+// the instance, if any
+func instanceGroupOwner(instance *cluster.Instance) string {
+	if ref := instanceGroupOwnerRef(instance); ref != nil {
+		return ref.Name
+	}
+	return ""
+}
+
+func instanceGroupOwnerRef(instance *cluster.Instance) *metav1.OwnerReference {
+	for i := range instance.OwnerReferences {
+		ref := &instance.OwnerReferences[i]
+		if ref.Controller != nil && *ref.Controller && ref.Kind == "InstanceGroup" {
+			return ref
+		}
+	}
+	return nil
+}
+
+// ownedInstances returns the instances controlled by the group, leaving
+// out others which merely match its selector
+func ownedInstances(in []cluster.Instance, ig *cluster.InstanceGroup) []cluster.Instance {
+	var owned []cluster.Instance
+	for i := range in {
+		if ref := instanceGroupOwnerRef(&in[i]); ref != nil && ref.UID == ig.UID {
+			owned = append(owned, in[i])
+		}
+	}
+	return owned
+}
+
+// instanceWaitState reduces the instance to a single state string for
+// waiting on it. Phases are reported as is until the instance is Running
+// and waitFor asks for more than that.
+func instanceWaitState(instance *cluster.Instance, waitFor string, minReadySeconds int32, now metav1.Time) string {
+	phase := instance.Status.Phase
+	if phase == "" {
+		// Not picked up by the controller yet
+		phase = cluster.InstancePending
+	}
+	if phase != cluster.InstanceRunning || waitFor == "running" {
+		return string(phase)
+	}
+
+	if !cluster.IsInstanceReady(instance) {
+		return "NotReady"
+	}
+	if waitFor == "ready" {
+		return "Ready"
+	}
+
+	if !cluster.IsInstanceAvailable(instance, minReadySeconds, now) {
+		return "NotAvailable"
+	}
+	return "Available"
+}
+
+// failingInstanceConditions returns the conditions which are not True
+func failingInstanceConditions(status cluster.InstanceStatus) []cluster.InstanceCondition {
+	var failing []cluster.InstanceCondition
+	for _, c := range status.Conditions {
+		if c.Status != api.ConditionTrue {
+			failing = append(failing, c)
+		}
+	}
+	return failing
+}
+
+func stringifyInstanceConditions(conditions []cluster.InstanceCondition) string {
+	var output string
+	for _, c := range conditions {
+		output += fmt.Sprintf("\n   * %s is %s: %s: %s", c.Type, c.Status, c.Reason, c.Message)
+	}
+	return output
+}
+
+// failingInstances returns the instances which failed or have conditions
+// which are not True
+func failingInstances(in []cluster.Instance) []cluster.Instance {
+	var failing []cluster.Instance
+	for _, i := range in {
+		switch {
+		case i.Status.Phase == cluster.InstanceFailed, i.Status.Phase == cluster.InstanceUnknown:
+		case len(failingInstanceConditions(i.Status)) > 0:
+		default:
+			continue
+		}
+		failing = append(failing, i)
+	}
+	return failing
+}
+
+// getInstanceGroupReplicaFailure returns the ReplicaFailure condition
+// of the group if it is True, otherwise nil
+func getInstanceGroupReplicaFailure(status cluster.InstanceGroupStatus) *cluster.InstanceGroupCondition {
+	for i := range status.Conditions {
+		c := &status.Conditions[i]
+		if c.Type == cluster.InstanceGroupReplicaFailure && c.Status == api.ConditionTrue {
+			return c
+		}
+	}
+	return nil
+}
+
+// instanceMatchesTemplate tells whether the instance carries the labels
+// and spec of the template. Fields the controller fills in per instance
+// are only compared when the template sets them.
+func instanceMatchesTemplate(instance *cluster.Instance, template *cluster.InstanceTemplateSpec) bool {
+	for k, v := range template.Labels {
+		if l, ok := instance.Labels[k]; !ok || l != v {
+			return false
+		}
+	}
+
+	spec := instance.Spec
+	templateSpec := template.Spec
+	if spec.ReservedInstanceRef != nil && templateSpec.ReservedInstanceRef == nil {
+		// The reserved instance an instance is bound to overrides these,
+		// see cluster.ReservedInstanceToInstance, so they can't be told
+		// apart from the template
+		spec.Image, templateSpec.Image = "", ""
+		spec.OS, templateSpec.OS = "", ""
+		spec.InstanceType, templateSpec.InstanceType = "", ""
+		spec.NetworkName, templateSpec.NetworkName = "", ""
+		spec.Configs, templateSpec.Configs = nil, nil
+	}
+	if templateSpec.Hostname == "" {
+		spec.Hostname = ""
+	}
+	if templateSpec.ReservedInstanceRef == nil {
+		spec.ReservedInstanceRef = nil
+	}
+	if templateSpec.ReclaimPolicy == "" {
+		spec.ReclaimPolicy = ""
+	}
+	return apiequality.Semantic.DeepEqual(spec, templateSpec)
+}
+
+// outdatedInstances returns the instances not matching the template,
+// leaving out those already being deleted
+func outdatedInstances(in []cluster.Instance, template *cluster.InstanceTemplateSpec) []cluster.Instance {
+	var outdated []cluster.Instance
+	for i := range in {
+		if in[i].DeletionTimestamp != nil || instanceMatchesTemplate(&in[i], template) {
+			continue
+		}
+		outdated = append(outdated, in[i])
+	}
+	return outdated
+}
+
+// waitForReferencedNetwork waits for the network an instance refers to
+// to be Running, so the instance isn't created into a network which is
+// still being set up. A missing network is left to checkInstanceReferences.
+func waitForReferencedNetwork(conn *archon.Clientset, namespace, name string, timeout time.Duration) error {
+	if name == "" {
+		return nil
+	}
+	_, err := conn.Archon().Networks(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Printf("[DEBUG] Referenced network %s/%s not found, not waiting for it", namespace, name)
+			return nil
+		}
+		return err
+	}
+
+	log.Printf("[DEBUG] Waiting for referenced network %s/%s to be running", namespace, name)
+	return waitForNetwork(conn, namespace, name, timeout)
+}
+
+// checkInstanceReferences looks up every object the instance spec refers
+// to. The API accepts dangling references, leaving the instance Pending,
+// so all missing or unusable ones are reported in a single error.
+func checkInstanceReferences(conn *archon.Clientset, namespace, instanceName string, spec cluster.InstanceSpec) error {
+	var problems []string
+
+	if spec.NetworkName != "" {
+		network, err := conn.Archon().Networks(namespace).Get(spec.NetworkName)
+		switch {
+		case errors.IsNotFound(err):
+			problems = append(problems, fmt.Sprintf("network %q not found", spec.NetworkName))
+		case err != nil:
+			return err
+		case network.Status.Phase != cluster.NetworkRunning:
+			problems = append(problems, fmt.Sprintf("network %q is %s, not %s",
+				spec.NetworkName, network.Status.Phase, cluster.NetworkRunning))
+		}
+	}
+
+	for _, u := range spec.Users {
+		_, err := conn.Archon().Users(namespace).Get(u.Name)
+		if errors.IsNotFound(err) {
+			problems = append(problems, fmt.Sprintf("user %q not found", u.Name))
+		} else if err != nil {
+			return err
+		}
+	}
+
+	for _, s := range spec.Secrets {
+		_, err := conn.CoreV1().Secrets(namespace).Get(s.Name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			problems = append(problems, fmt.Sprintf("secret %q not found", s.Name))
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if ref := spec.ReservedInstanceRef; ref != nil && ref.Name != "" {
+		ri, err := conn.Archon().ReservedInstances(namespace).Get(ref.Name)
+		switch {
+		case errors.IsNotFound(err):
+			problems = append(problems, fmt.Sprintf("reserved instance %q not found", ref.Name))
+		case err != nil:
+			return err
+		case ri.Status.Phase == cluster.ReservedInstanceAvailable:
+		case ri.Status.Phase == cluster.ReservedInstanceBound && ri.Status.InstanceName == instanceName:
+		case ri.Status.Phase == cluster.ReservedInstanceBound:
+			problems = append(problems, fmt.Sprintf("reserved instance %q is bound to instance %q",
+				ref.Name, ri.Status.InstanceName))
+		default:
+			problems = append(problems, fmt.Sprintf("reserved instance %q is %s, not %s",
+				ref.Name, ri.Status.Phase, cluster.ReservedInstanceAvailable))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	var output string
+	for _, p := range problems {
+		output += fmt.Sprintf("\n   * %s", p)
+	}
+	return fmt.Errorf("Instance refers to missing or unusable objects in namespace %q:%s", namespace, output)
+}
@@ -0,0 +1,277 @@
+package kubernetes
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/api"
+	"kubeup.com/archon/pkg/cluster"
+)
+
+func TestFilterInstances(t *testing.T) {
+	instance := func(name, app string, phase cluster.InstancePhase) cluster.Instance {
+		return cluster.Instance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"app": app},
+			},
+			Status: cluster.InstanceStatus{Phase: phase},
+		}
+	}
+	items := []cluster.Instance{
+		instance("web-c", "web", cluster.InstanceRunning),
+		instance("db-a", "db", cluster.InstanceRunning),
+		instance("web-a", "web", cluster.InstancePending),
+		instance("web-b", "web", cluster.InstanceRunning),
+	}
+
+	testCases := []struct {
+		Selector      labels.Selector
+		Phase         cluster.InstancePhase
+		ExpectedNames []string
+	}{
+		{
+			Selector:      labels.Everything(),
+			ExpectedNames: []string{"db-a", "web-a", "web-b", "web-c"},
+		},
+		{
+			Selector:      labels.SelectorFromSet(labels.Set{"app": "web"}),
+			ExpectedNames: []string{"web-a", "web-b", "web-c"},
+		},
+		{
+			Selector:      labels.SelectorFromSet(labels.Set{"app": "web"}),
+			Phase:         cluster.InstanceRunning,
+			ExpectedNames: []string{"web-b", "web-c"},
+		},
+		{
+			Selector:      labels.SelectorFromSet(labels.Set{"app": "cache"}),
+			ExpectedNames: []string{},
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			out := filterInstances(items, tc.Selector, tc.Phase)
+			names := make([]string, len(out))
+			for j, v := range out {
+				names[j] = v.Name
+			}
+			if !reflect.DeepEqual(names, tc.ExpectedNames) {
+				t.Fatalf("Expected %q, given %q", tc.ExpectedNames, names)
+			}
+		})
+	}
+}
+
+func TestInstanceWaitState(t *testing.T) {
+	now := metav1.Now()
+	instance := func(phase cluster.InstancePhase, ready api.ConditionStatus, readySince time.Duration) *cluster.Instance {
+		i := &cluster.Instance{
+			Status: cluster.InstanceStatus{Phase: phase},
+		}
+		if ready != "" {
+			i.Status.Conditions = []cluster.InstanceCondition{
+				{
+					Type:               cluster.InstanceReady,
+					Status:             ready,
+					LastTransitionTime: metav1.NewTime(now.Add(-readySince)),
+				},
+			}
+		}
+		return i
+	}
+
+	testCases := []struct {
+		Instance        *cluster.Instance
+		WaitFor         string
+		MinReadySeconds int32
+		Expected        string
+	}{
+		{instance("", "", 0), "running", 0, "Pending"},
+		{instance(cluster.InstancePending, "", 0), "ready", 0, "Pending"},
+		{instance(cluster.InstanceInitializing, "", 0), "running", 0, "Initializing"},
+		{instance(cluster.InstanceFailed, "", 0), "available", 0, "Failed"},
+		{instance(cluster.InstanceRunning, "", 0), "running", 0, "Running"},
+		{instance(cluster.InstanceRunning, "", 0), "ready", 0, "NotReady"},
+		{instance(cluster.InstanceRunning, api.ConditionFalse, time.Minute), "ready", 0, "NotReady"},
+		{instance(cluster.InstanceRunning, api.ConditionTrue, time.Minute), "ready", 0, "Ready"},
+		{instance(cluster.InstanceRunning, api.ConditionTrue, time.Second), "available", 0, "Available"},
+		{instance(cluster.InstanceRunning, api.ConditionTrue, time.Second), "available", 30, "NotAvailable"},
+		{instance(cluster.InstanceRunning, api.ConditionTrue, time.Minute), "available", 30, "Available"},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			state := instanceWaitState(tc.Instance, tc.WaitFor, tc.MinReadySeconds, now)
+			if state != tc.Expected {
+				t.Fatalf("Expected %q, given %q", tc.Expected, state)
+			}
+		})
+	}
+}
+
+func TestGetInstanceGroupReplicaFailure(t *testing.T) {
+	condition := func(t cluster.InstanceGroupConditionType, s api.ConditionStatus) cluster.InstanceGroupCondition {
+		return cluster.InstanceGroupCondition{Type: t, Status: s, Reason: "FailedCreate", Message: "quota exceeded"}
+	}
+
+	testCases := []struct {
+		Conditions []cluster.InstanceGroupCondition
+		Failed     bool
+	}{
+		{nil, false},
+		{[]cluster.InstanceGroupCondition{condition(cluster.InstanceGroupReplicaFailure, api.ConditionFalse)}, false},
+		{[]cluster.InstanceGroupCondition{condition("Other", api.ConditionTrue)}, false},
+		{[]cluster.InstanceGroupCondition{
+			condition("Other", api.ConditionTrue),
+			condition(cluster.InstanceGroupReplicaFailure, api.ConditionTrue),
+		}, true},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			status := cluster.InstanceGroupStatus{Conditions: tc.Conditions}
+			c := getInstanceGroupReplicaFailure(status)
+			if tc.Failed && (c == nil || c.Type != cluster.InstanceGroupReplicaFailure) {
+				t.Fatalf("Expected ReplicaFailure in %#v, got %#v", tc.Conditions, c)
+			}
+			if !tc.Failed && c != nil {
+				t.Fatalf("Expected no ReplicaFailure in %#v, got %#v", tc.Conditions, c)
+			}
+		})
+	}
+}
+
+func TestFailingInstances(t *testing.T) {
+	instance := func(name string, phase cluster.InstancePhase, ready api.ConditionStatus) cluster.Instance {
+		i := cluster.Instance{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     cluster.InstanceStatus{Phase: phase},
+		}
+		if ready != "" {
+			i.Status.Conditions = []cluster.InstanceCondition{
+				{Type: cluster.InstanceReady, Status: ready},
+			}
+		}
+		return i
+	}
+	items := []cluster.Instance{
+		instance("a", cluster.InstanceRunning, api.ConditionTrue),
+		instance("b", cluster.InstanceFailed, ""),
+		instance("c", cluster.InstancePending, ""),
+		instance("d", cluster.InstanceRunning, api.ConditionFalse),
+		instance("e", cluster.InstanceUnknown, ""),
+	}
+
+	var names []string
+	for _, i := range failingInstances(items) {
+		names = append(names, i.Name)
+	}
+	expected := []string{"b", "d", "e"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Expected failing instances %q, got %q", expected, names)
+	}
+}
+
+func TestOwnedInstances(t *testing.T) {
+	isController := true
+	notController := false
+	ig := &cluster.InstanceGroup{}
+	ig.Name = "web"
+	ig.UID = "uid-web"
+
+	instance := func(name, kind, owner string, uid types.UID, controller *bool) cluster.Instance {
+		i := cluster.Instance{}
+		i.Name = name
+		if owner != "" {
+			i.OwnerReferences = []metav1.OwnerReference{
+				{Kind: kind, Name: owner, UID: uid, Controller: controller},
+			}
+		}
+		return i
+	}
+	items := []cluster.Instance{
+		instance("web-1", "InstanceGroup", "web", "uid-web", &isController),
+		// Shares the labels, but isn't part of the group
+		instance("standalone", "", "", "", nil),
+		// Left over from an earlier group of the same name
+		instance("web-old", "InstanceGroup", "web", "uid-old", &isController),
+		instance("other-1", "InstanceGroup", "other", "uid-other", &isController),
+		instance("web-2", "InstanceGroup", "web", "uid-web", &notController),
+		instance("web-3", "InstanceGroup", "web", "uid-web", &isController),
+	}
+
+	var names []string
+	for _, i := range ownedInstances(items, ig) {
+		names = append(names, i.Name)
+	}
+	expected := []string{"web-1", "web-3"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Expected owned instances %q, got %q", expected, names)
+	}
+}
+
+func TestOutdatedInstances(t *testing.T) {
+	template := &cluster.InstanceTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"app": "web", "version": "two"},
+		},
+		Spec: cluster.InstanceSpec{
+			Image:       "second",
+			NetworkName: "net",
+			Users:       []cluster.LocalObjectReference{{Name: "core"}},
+		},
+	}
+	instance := func(name, version, image string) cluster.Instance {
+		return cluster.Instance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"app": "web", "version": version, "extra": "x"},
+			},
+			Spec: cluster.InstanceSpec{
+				Image:       image,
+				NetworkName: "net",
+				Users:       []cluster.LocalObjectReference{{Name: "core"}},
+				// Filled in by the controller
+				Hostname: name,
+			},
+		}
+	}
+	// Members bound to a reserved instance get its spec merged in
+	reserved := func(name string, users []cluster.LocalObjectReference) cluster.Instance {
+		i := instance(name, "two", "reserved-image")
+		i.Spec.OS = "CoreOS"
+		i.Spec.InstanceType = "large"
+		i.Spec.NetworkName = "reserved-net"
+		i.Spec.Configs = []cluster.ConfigSpec{{Name: "kubelet", Data: map[string]string{"labels": "reserved"}}}
+		i.Spec.Users = users
+		i.Spec.ReservedInstanceRef = &cluster.LocalObjectReference{Name: "reserved-" + name}
+		return i
+	}
+	deleting := instance("e", "one", "first")
+	deleting.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	noUsers := instance("f", "two", "second")
+	noUsers.Spec.Users = nil
+
+	items := []cluster.Instance{
+		instance("a", "two", "second"),
+		instance("b", "one", "second"),
+		instance("c", "two", "first"),
+		instance("d", "one", "first"),
+		deleting,
+		noUsers,
+		reserved("g", []cluster.LocalObjectReference{{Name: "core"}}),
+		reserved("h", nil),
+	}
+
+	var names []string
+	for _, i := range outdatedInstances(items, template) {
+		names = append(names, i.Name)
+	}
+	expected := []string{"b", "c", "d", "f", "h"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Expected outdated instances %q, got %q", expected, names)
+	}
+}
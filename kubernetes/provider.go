@@ -92,10 +92,16 @@ func Provider() terraform.ResourceProvider {
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"archon_user":          resourceArchonUser(),
-			"archon_network":       resourceArchonNetwork(),
-			"archon_instance":      resourceArchonInstance(),
-			"archon_instancegroup": resourceArchonInstanceGroup(),
+			"archon_user":              resourceArchonUser(),
+			"archon_network":           resourceArchonNetwork(),
+			"archon_instance":          resourceArchonInstance(),
+			"archon_instancegroup":     resourceArchonInstanceGroup(),
+			"archon_reserved_instance": resourceArchonReservedInstance(),
+			"archon_secret":            resourceArchonSecret(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"archon_instance":  dataSourceArchonInstance(),
+			"archon_instances": dataSourceArchonInstances(),
 		},
 		ConfigureFunc: providerConfigure,
 	}
@@ -4,15 +4,19 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform/builtin/providers/google"
 	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
+	"k8s.io/apimachinery/pkg/api/errors"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	archon "kubeup.com/archon/pkg/clientset"
 )
 
 var testAccProviders map[string]terraform.ResourceProvider
@@ -211,3 +215,134 @@ func testAccCheckMetaLabels(om *meta_v1.ObjectMeta, expected map[string]string)
 		return nil
 	}
 }
+
+// testAccArchonClient gets and deletes the objects behind one resource type,
+// so the checks below can be shared by every resource
+type testAccArchonClient struct {
+	Get    func(namespace, name string) (interface{}, error)
+	Delete func(namespace, name string) error
+}
+
+type testAccArchonClientFunc func(conn *archon.Clientset) testAccArchonClient
+
+func testAccCheckArchonDestroy(resourceType string, client testAccArchonClientFunc) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		c := client(testAccProvider.Meta().(*archon.Clientset))
+
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != resourceType {
+				continue
+			}
+			namespace, name := idParts(rs.Primary.ID)
+			_, err := c.Get(namespace, name)
+			if err == nil {
+				return fmt.Errorf("%s still exists: %s", resourceType, rs.Primary.ID)
+			}
+			if !errors.IsNotFound(err) {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckArchonExists copies the object behind n into obj, which must
+// be a pointer to the type the client returns. A nil obj only checks that
+// the object exists.
+func testAccCheckArchonExists(n string, client testAccArchonClientFunc, obj interface{}) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		c := client(testAccProvider.Meta().(*archon.Clientset))
+		namespace, name := idParts(rs.Primary.ID)
+		out, err := c.Get(namespace, name)
+		if err != nil {
+			return err
+		}
+
+		if obj != nil {
+			reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(out).Elem())
+		}
+		return nil
+	}
+}
+
+// testAccCheckArchonDisappears deletes the object behind n outside of
+// Terraform and waits for it to be gone
+func testAccCheckArchonDisappears(n string, client testAccArchonClientFunc) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		c := client(testAccProvider.Meta().(*archon.Clientset))
+		namespace, name := idParts(rs.Primary.ID)
+		err := c.Delete(namespace, name)
+		if err != nil {
+			return err
+		}
+
+		return resource.Retry(5*time.Minute, func() *resource.RetryError {
+			_, err := c.Get(namespace, name)
+			if err != nil {
+				if errors.IsNotFound(err) {
+					return nil
+				}
+				return resource.NonRetryableError(err)
+			}
+			return resource.RetryableError(fmt.Errorf("%s still exists", rs.Primary.ID))
+		})
+	}
+}
+
+// testAccArchonDisappearsTest applies config, deletes the resourceType.test
+// object behind Terraform's back and expects the plan to recreate it
+func testAccArchonDisappearsTest(t *testing.T, resourceType string, client testAccArchonClientFunc, config string) {
+	n := resourceType + ".test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckArchonDestroy(resourceType, client),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckArchonExists(n, client, nil),
+					testAccCheckArchonDisappears(n, client),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+// testAccArchonGeneratedNameTest applies the config returned for a prefix,
+// which only sets generate_name, and checks the server-assigned name is used
+func testAccArchonGeneratedNameTest(t *testing.T, resourceType string, client testAccArchonClientFunc, config func(prefix string) string) {
+	n := resourceType + ".test"
+	prefix := "tf-acc-test-gen-"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:      func() { testAccPreCheck(t) },
+		IDRefreshName: n,
+		Providers:     testAccProviders,
+		CheckDestroy:  testAccCheckArchonDestroy(resourceType, client),
+		Steps: []resource.TestStep{
+			{
+				Config: config(prefix),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckArchonExists(n, client, nil),
+					resource.TestCheckResourceAttr(n, "metadata.0.generate_name", prefix),
+					resource.TestMatchResourceAttr(n, "metadata.0.name", regexp.MustCompile("^"+prefix)),
+					resource.TestCheckResourceAttrSet(n, "metadata.0.uid"),
+				),
+			},
+		},
+	})
+}
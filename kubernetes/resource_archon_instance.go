@@ -8,6 +8,7 @@ import (
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	pkgApi "k8s.io/apimachinery/pkg/types"
 	archon "kubeup.com/archon/pkg/clientset"
 	"kubeup.com/archon/pkg/cluster"
@@ -17,12 +18,15 @@ func resourceArchonInstance() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceArchonInstanceCreate,
 		Read:   resourceArchonInstanceRead,
-		Exists: resourceArchonInstanceExists,
 		Update: resourceArchonInstanceUpdate,
 		Delete: resourceArchonInstanceDelete,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"metadata": namespacedMetadataSchema("instance", true),
@@ -36,6 +40,30 @@ func resourceArchonInstance() *schema.Resource {
 					Schema: instanceSpecFields(),
 				},
 			},
+			"wait_for": {
+				Type:        schema.TypeString,
+				Description: "What to wait for after creating the instance. One of `none`, `running`, `ready` or `available`.",
+				Optional:    true,
+				Default:     "running",
+				ValidateFunc: validateAttributeValueIsIn([]string{
+					"none", "running", "ready", "available",
+				}),
+			},
+			"min_ready_seconds": {
+				Type:         schema.TypeInt,
+				Description:  "Minimum number of seconds the instance has to be ready to be considered available. Used with `wait_for = \"available\"`.",
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validateNonNegativeInteger,
+			},
+			"status": {
+				Type:        schema.TypeList,
+				Description: "Archon Instance status",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: instanceStatusFields(),
+				},
+			},
 		},
 	}
 }
@@ -44,43 +72,50 @@ func resourceArchonInstanceCreate(d *schema.ResourceData, meta interface{}) erro
 	conn := meta.(*archon.Clientset)
 
 	metadata := expandMetadata(d.Get("metadata").([]interface{}))
+	spec, err := expandInstanceSpec(d.Get("spec").([]interface{}))
+	if err != nil {
+		return err
+	}
 	instance := cluster.Instance{
 		ObjectMeta: metadata,
-		Spec:       expandInstanceSpec(d.Get("spec").([]interface{})),
+		Spec:       spec,
 	}
-	log.Printf("[INFO] Creating new instance: %#v", instance)
+	err = validateFileSpecs("spec.0.files.", d.Get("spec.0.files").([]interface{}))
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = waitForReferencedNetwork(conn, metadata.Namespace, instance.Spec.NetworkName, d.Timeout(schema.TimeoutCreate))
+	if err != nil {
+		return err
+	}
+	err = checkInstanceReferences(conn, metadata.Namespace, metadata.Name, instance.Spec)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Creating new instance: %s", metadata.Name)
 	out, err := conn.Archon().Instances(metadata.Namespace).Create(&instance)
 	if err != nil {
 		return err
 	}
-	log.Printf("[INFO] Submitted new instance: %#v", out)
+	log.Printf("[INFO] Submitted new instance: %s", out.Name)
 	d.SetId(buildId(out.ObjectMeta))
 
-	stateConf := &resource.StateChangeConf{
-		Target:  []string{"Running"},
-		Pending: []string{"Pending"},
-		Timeout: 5 * time.Minute,
-		Refresh: func() (interface{}, string, error) {
-			out, err := conn.Archon().Instances(metadata.Namespace).Get(metadata.Name)
-			if err != nil {
-				log.Printf("[ERROR] Received error: %#v", err)
-				return out, "Error", err
-			}
-
-			statusPhase := fmt.Sprintf("%v", out.Status.Phase)
-			log.Printf("[DEBUG] Instance %s status received: %#v", out.Name, statusPhase)
-			return out, statusPhase, nil
-		},
+	waitFor := d.Get("wait_for").(string)
+	if waitFor == "none" {
+		log.Printf("[INFO] Instance %s created, not waiting for it", out.Name)
+		return resourceArchonInstanceRead(d, meta)
 	}
-	_, err = stateConf.WaitForState()
+
+	minReadySeconds := int32(d.Get("min_ready_seconds").(int))
+	// Name may have been generated by the server
+	err = waitForInstance(conn, out.ObjectMeta, waitFor, minReadySeconds, d.Timeout(schema.TimeoutCreate)-time.Since(start))
 	if err != nil {
-		lastWarnings, wErr := getLastWarningsForObject(conn, out.ObjectMeta, "Instance", 3)
-		if wErr != nil {
-			return wErr
-		}
-		return fmt.Errorf("%s%s", err, stringifyEvents(lastWarnings))
+		return err
 	}
-	log.Printf("[INFO] Network %s created", out.Name)
+	log.Printf("[INFO] Instance %s created", out.Name)
 
 	return resourceArchonInstanceRead(d, meta)
 }
@@ -92,22 +127,36 @@ func resourceArchonInstanceRead(d *schema.ResourceData, meta interface{}) error
 	log.Printf("[INFO] Reading instance %s", name)
 	instance, err := conn.Archon().Instances(namespace).Get(name)
 	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Printf("[WARN] Instance %s not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
 		log.Printf("[DEBUG] Received error: %#v", err)
 		return err
 	}
-	log.Printf("[INFO] Received instance: %#v", instance)
+	log.Printf("[INFO] Received instance: %s", instance.Name)
 	err = d.Set("metadata", flattenMetadata(instance.ObjectMeta))
 	if err != nil {
 		return err
 	}
 
 	flattened := flattenInstanceSpec(instance.Spec)
-	log.Printf("[DEBUG] Flattened instance spec: %#v", flattened)
+	err = flattenAutoEncodedFiles(flattened, d.Get("spec").([]interface{}))
+	if err != nil {
+		return err
+	}
+	flattenConfigSources(flattened, d.Get("spec").([]interface{}))
 	err = d.Set("spec", flattened)
 	if err != nil {
 		return err
 	}
 
+	err = d.Set("status", flattenInstanceStatus(instance.Status))
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -122,12 +171,12 @@ func resourceArchonInstanceUpdate(d *schema.ResourceData, meta interface{}) erro
 		return fmt.Errorf("Failed to marshal update operations: %s", err)
 	}
 
-	log.Printf("[INFO] Updating instance: %s", ops)
+	log.Printf("[INFO] Updating instance %s: %d operations", name, len(ops))
 	out, err := conn.Archon().Instances(namespace).Patch(name, pkgApi.JSONPatchType, data)
 	if err != nil {
 		return err
 	}
-	log.Printf("[INFO] Submitted updated instance: %#v", out)
+	log.Printf("[INFO] Submitted updated instance: %s", out.Name)
 	d.SetId(buildId(out.ObjectMeta))
 
 	return resourceArchonInstanceRead(d, meta)
@@ -143,24 +192,75 @@ func resourceArchonInstanceDelete(d *schema.ResourceData, meta interface{}) erro
 		return err
 	}
 
+	err = resource.Retry(d.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
+		_, err := conn.Archon().Instances(namespace).Get(name)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return resource.NonRetryableError(err)
+		}
+
+		e := fmt.Errorf("Instance %s still exists", name)
+		return resource.RetryableError(e)
+	})
+	if err != nil {
+		objectMeta := metav1.ObjectMeta{Namespace: namespace, Name: name}
+		lastWarnings, wErr := getLastWarningsForObject(conn, objectMeta, "Instance", 3)
+		if wErr != nil {
+			return wErr
+		}
+		return fmt.Errorf("%s%s", err, stringifyEvents(lastWarnings))
+	}
+
 	log.Printf("[INFO] Instance %s deleted", name)
 
 	d.SetId("")
 	return nil
 }
 
-func resourceArchonInstanceExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	conn := meta.(*archon.Clientset)
+func waitForInstance(conn *archon.Clientset, objectMeta metav1.ObjectMeta, waitFor string, minReadySeconds int32, timeout time.Duration) error {
+	targets := map[string]string{
+		"running":   "Running",
+		"ready":     "Ready",
+		"available": "Available",
+	}
 
-	namespace, name := idParts(d.Id())
-	log.Printf("[INFO] Checking instance %s", name)
-	_, err := conn.Archon().Instances(namespace).Get(name)
+	var last *cluster.Instance
+	stateConf := &resource.StateChangeConf{
+		Target:  []string{targets[waitFor]},
+		Pending: []string{"Pending", "Initializing", "Running", "NotReady", "NotAvailable"},
+		Timeout: timeout,
+		Refresh: func() (interface{}, string, error) {
+			out, err := conn.Archon().Instances(objectMeta.Namespace).Get(objectMeta.Name)
+			if err != nil {
+				log.Printf("[ERROR] Received error: %#v", err)
+				return out, "Error", err
+			}
+			last = out
+
+			state := instanceWaitState(out, waitFor, minReadySeconds, metav1.Now())
+			log.Printf("[DEBUG] Instance %s state received: %#v", out.Name, state)
+
+			phase := out.Status.Phase
+			if phase == cluster.InstanceFailed || phase == cluster.InstanceUnknown {
+				return out, state, fmt.Errorf("Instance %s entered phase %s", out.Name, phase)
+			}
+			return out, state, nil
+		},
+	}
+	_, err := stateConf.WaitForState()
 	if err != nil {
-		if statusErr, ok := err.(*errors.StatusError); ok && statusErr.ErrStatus.Code == 404 {
-			return false, nil
+		var conditions string
+		if last != nil {
+			conditions = stringifyInstanceConditions(failingInstanceConditions(last.Status))
 		}
-		log.Printf("[DEBUG] Received error: %#v", err)
+		lastWarnings, wErr := getLastWarningsForObject(conn, objectMeta, "Instance", 3)
+		if wErr != nil {
+			return wErr
+		}
+		return fmt.Errorf("%s%s%s", err, conditions, stringifyEvents(lastWarnings))
 	}
-	log.Printf("[INFO] Instance %s exists", name)
-	return true, err
+
+	return nil
 }
@@ -3,10 +3,12 @@ package kubernetes
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	pkgApi "k8s.io/apimachinery/pkg/types"
 	archon "kubeup.com/archon/pkg/clientset"
 	"kubeup.com/archon/pkg/cluster"
@@ -16,12 +18,16 @@ func resourceArchonInstanceGroup() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceArchonInstanceGroupCreate,
 		Read:   resourceArchonInstanceGroupRead,
-		Exists: resourceArchonInstanceGroupExists,
 		Update: resourceArchonInstanceGroupUpdate,
 		Delete: resourceArchonInstanceGroupDelete,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"metadata": namespacedMetadataSchema("instance_group", true),
@@ -36,11 +42,18 @@ func resourceArchonInstanceGroup() *schema.Resource {
 							Type:     schema.TypeInt,
 							Required: true,
 						},
+						"min_ready_seconds": {
+							Type:         schema.TypeInt,
+							Description:  "Minimum number of seconds for which a newly created instance should be ready without failing, for it to be considered available.",
+							Optional:     true,
+							ValidateFunc: validateNonNegativeInteger,
+						},
 						"provision_policy": {
-							Type:     schema.TypeString,
-							Optional: true,
-							ForceNew: true,
-							Default:  "DynamicOnly",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ForceNew:     true,
+							Default:      string(cluster.InstanceGroupProvisionDynamicOnly),
+							ValidateFunc: validateProvisionPolicy,
 						},
 						"selector": {
 							Type:        schema.TypeList,
@@ -140,7 +153,6 @@ func resourceArchonInstanceGroup() *schema.Resource {
 							Type:        schema.TypeList,
 							Description: "Archon Instance spec",
 							Required:    true,
-							ForceNew:    true,
 							MaxItems:    1,
 							Elem: &schema.Resource{
 								Schema: map[string]*schema.Schema{
@@ -149,7 +161,6 @@ func resourceArchonInstanceGroup() *schema.Resource {
 										Type:        schema.TypeList,
 										Description: "Archon Instance spec",
 										Required:    true,
-										ForceNew:    true,
 										MaxItems:    1,
 										Elem: &schema.Resource{
 											Schema: instanceSpecFields(),
@@ -158,7 +169,6 @@ func resourceArchonInstanceGroup() *schema.Resource {
 									"secrets": {
 										Type:     schema.TypeList,
 										Optional: true,
-										ForceNew: true,
 										Elem: &schema.Resource{
 											Schema: map[string]*schema.Schema{
 												"metadata": namespacedMetadataSchema("secret", true),
@@ -169,11 +179,11 @@ func resourceArchonInstanceGroup() *schema.Resource {
 													Sensitive:   true,
 												},
 												"type": {
-													Type:        schema.TypeString,
-													Description: "Type of secret",
-													Default:     "Opaque",
-													Optional:    true,
-													ForceNew:    true,
+													Type:         schema.TypeString,
+													Description:  "Type of secret",
+													Default:      "Opaque",
+													Optional:     true,
+													ValidateFunc: validateSecretType,
 												},
 											},
 										},
@@ -184,6 +194,113 @@ func resourceArchonInstanceGroup() *schema.Resource {
 					},
 				},
 			},
+			"wait_for": {
+				Type:        schema.TypeString,
+				Description: "What to wait for after creating the instance group. One of `scheduled`, `ready` or `available` replicas.",
+				Optional:    true,
+				Default:     "scheduled",
+				ValidateFunc: validateAttributeValueIsIn([]string{
+					"scheduled", "ready", "available",
+				}),
+			},
+			"rolling_update": {
+				Type:        schema.TypeList,
+				Description: "Replace members not matching the template, batch by batch, after the template is updated.",
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"max_unavailable": {
+							Type:         schema.TypeInt,
+							Description:  "Number of outdated instances deleted in a batch before their replacements are up.",
+							Optional:     true,
+							Default:      1,
+							ValidateFunc: validateNonNegativeInteger,
+						},
+						"max_surge": {
+							Type:         schema.TypeInt,
+							Description:  "Number of instances created above the desired replicas in a batch to replace outdated ones.",
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: validateNonNegativeInteger,
+						},
+						"batch_pause": {
+							Type:         schema.TypeString,
+							Description:  "Time to wait between batches, e.g. `30s`.",
+							Optional:     true,
+							Default:      "0s",
+							ValidateFunc: validateDuration,
+						},
+						"wait_for_ready": {
+							Type:        schema.TypeBool,
+							Description: "Wait for replacements to be ready instead of scheduled before moving on.",
+							Optional:    true,
+							Default:     true,
+						},
+					},
+				},
+			},
+			"status": {
+				Type:        schema.TypeList,
+				Description: "Archon InstanceGroup status",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"replicas": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"fully_labeled_replicas": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"ready_replicas": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"available_replicas": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"observed_generation": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"conditions": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"type": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"status": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"reason": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"message": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"instances": {
+				Type:        schema.TypeList,
+				Description: "Current members of the instance group, sorted by name.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: instanceSummaryFields(),
+				},
+			},
 		},
 	}
 }
@@ -192,28 +309,46 @@ func resourceArchonInstanceGroupCreate(d *schema.ResourceData, meta interface{})
 	conn := meta.(*archon.Clientset)
 
 	metadata := expandMetadata(d.Get("metadata").([]interface{}))
+	spec, err := expandInstanceGroupSpec(d.Get("spec").([]interface{}))
+	if err != nil {
+		return err
+	}
 	instanceGroup := cluster.InstanceGroup{
 		ObjectMeta: metadata,
-		Spec:       expandInstanceGroupSpec(d.Get("spec").([]interface{})),
+		Spec:       spec,
 	}
-	log.Printf("[INFO] Creating new instance_group: %#v", instanceGroup)
+
+	err = validateFileSpecs("spec.0.template.0.spec.0.files.",
+		d.Get("spec.0.template.0.spec.0.files").([]interface{}))
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = waitForReferencedNetwork(conn, metadata.Namespace, instanceGroup.Spec.Template.Spec.NetworkName,
+		d.Timeout(schema.TimeoutCreate))
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Creating new instance_group: %s", metadata.Name)
 	out, err := conn.Archon().InstanceGroups(metadata.Namespace).Create(&instanceGroup)
 	if err != nil {
 		return err
 	}
-	log.Printf("[INFO] Submitted new instance_group: %#v", out)
+	log.Printf("[INFO] Submitted new instance_group: %s", out.Name)
 	d.SetId(buildId(out.ObjectMeta))
 
-	log.Printf("[DEBUG] Waiting for instance group %s to schedule %d replicas",
-		d.Id(), out.Spec.Replicas)
+	waitFor := d.Get("wait_for").(string)
+	log.Printf("[DEBUG] Waiting for instance group %s to have %d %s replicas",
+		d.Id(), out.Spec.Replicas, waitFor)
 	// 10 mins should be sufficient for scheduling ~10k replicas
-	err = resource.Retry(d.Timeout(schema.TimeoutCreate),
-		waitForDesiredReplicasFunc(conn, out.GetNamespace(), out.GetName()))
+	err = waitForInstanceGroup(conn, out.GetNamespace(), out.GetName(), waitFor, d.Timeout(schema.TimeoutCreate)-time.Since(start))
 	if err != nil {
 		return err
 	}
 
-	log.Printf("[INFO] Submitted new instance group: %#v", out)
+	log.Printf("[INFO] Submitted new instance group: %s", out.Name)
 
 	return resourceArchonInstanceGroupRead(d, meta)
 }
@@ -225,22 +360,62 @@ func resourceArchonInstanceGroupRead(d *schema.ResourceData, meta interface{}) e
 	log.Printf("[INFO] Reading instance_group %s", name)
 	instanceGroup, err := conn.Archon().InstanceGroups(namespace).Get(name)
 	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Printf("[WARN] InstanceGroup %s not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
 		log.Printf("[DEBUG] Received error: %#v", err)
 		return err
 	}
-	log.Printf("[INFO] Received instance_group: %#v", instanceGroup)
+	log.Printf("[INFO] Received instance_group: %s", instanceGroup.Name)
 	err = d.Set("metadata", flattenMetadata(instanceGroup.ObjectMeta))
 	if err != nil {
 		return err
 	}
 
 	flattened := flattenInstanceGroupSpec(instanceGroup.Spec)
-	log.Printf("[DEBUG] Flattened instance_group spec: %#v", flattened)
+	template := flattened[0].(map[string]interface{})["template"].([]interface{})
+	templateSpec := template[0].(map[string]interface{})["spec"].([]interface{})
+	priorTemplateSpec := d.Get("spec.0.template.0.spec").([]interface{})
+	err = flattenAutoEncodedFiles(templateSpec, priorTemplateSpec)
+	if err != nil {
+		return err
+	}
+	flattenConfigSources(templateSpec, priorTemplateSpec)
 	err = d.Set("spec", flattened)
 	if err != nil {
 		return err
 	}
 
+	err = d.Set("status", flattenInstanceGroupStatus(instanceGroup.Status))
+	if err != nil {
+		return err
+	}
+
+	instances, err := listInstancesBySelector(conn, namespace, instanceGroupSelector(instanceGroup), "")
+	if err != nil {
+		return err
+	}
+	err = d.Set("instances", flattenInstanceSummaries(instances))
+	if err != nil {
+		return err
+	}
+
+	// Members left outdated by a failed rolling update would otherwise go
+	// unnoticed, as the template in state already matches the config.
+	// Dropping rolling_update from state makes the next apply resume it.
+	if len(d.Get("rolling_update").([]interface{})) > 0 {
+		outdated := outdatedInstances(ownedInstances(instances, instanceGroup), &instanceGroup.Spec.Template)
+		if len(outdated) > 0 {
+			log.Printf("[WARN] %d instances of %s don't match the template, rolling update is incomplete", len(outdated), d.Id())
+			err = d.Set("rolling_update", []interface{}{})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -249,9 +424,24 @@ func resourceArchonInstanceGroupUpdate(d *schema.ResourceData, meta interface{})
 
 	namespace, name := idParts(d.Id())
 
+	if d.HasChange("spec.0.template") {
+		err := validateFileSpecs("spec.0.template.0.spec.0.files.",
+			d.Get("spec.0.template.0.spec.0.files").([]interface{}))
+		if err != nil {
+			return err
+		}
+	}
+
 	ops := patchMetadata("metadata.0.", "/metadata/", d)
 	if d.HasChange("spec") {
-		diffOps := patchInstanceGroupSpec("spec.0.", "/spec/", d)
+		current, err := conn.Archon().InstanceGroups(namespace).Get(name)
+		if err != nil {
+			return err
+		}
+		diffOps, err := patchInstanceGroupSpec("spec.0.", "/spec/", d, current.Spec)
+		if err != nil {
+			return err
+		}
 		ops = append(ops, diffOps...)
 	}
 	data, err := ops.MarshalJSON()
@@ -259,14 +449,35 @@ func resourceArchonInstanceGroupUpdate(d *schema.ResourceData, meta interface{})
 		return fmt.Errorf("Failed to marshal update operations: %s", err)
 	}
 
-	log.Printf("[INFO] Updating instance_group: %s", ops)
+	log.Printf("[INFO] Updating instance_group %s: %d operations", name, len(ops))
 	out, err := conn.Archon().InstanceGroups(namespace).Patch(name, pkgApi.JSONPatchType, data)
 	if err != nil {
 		return err
 	}
-	log.Printf("[INFO] Submitted updated instance_group: %#v", out)
+	log.Printf("[INFO] Submitted updated instance_group: %s", out.Name)
 	d.SetId(buildId(out.ObjectMeta))
 
+	start := time.Now()
+	if d.HasChange("spec.0.replicas") {
+		waitFor := d.Get("wait_for").(string)
+		log.Printf("[DEBUG] Waiting for instance group %s to have %d %s replicas",
+			d.Id(), out.Spec.Replicas, waitFor)
+		err = waitForInstanceGroup(conn, out.GetNamespace(), out.GetName(), waitFor, d.Timeout(schema.TimeoutUpdate)-time.Since(start))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Not only when the template changed: a roll which failed or timed
+	// out is resumed here, see resourceArchonInstanceGroupRead
+	rollingUpdate := expandRollingUpdate(d.Get("rolling_update").([]interface{}))
+	if rollingUpdate != nil {
+		err = rollInstanceGroup(conn, out.GetNamespace(), out.GetName(), rollingUpdate, d.Timeout(schema.TimeoutUpdate)-time.Since(start))
+		if err != nil {
+			return err
+		}
+	}
+
 	return resourceArchonInstanceGroupRead(d, meta)
 }
 
@@ -280,44 +491,285 @@ func resourceArchonInstanceGroupDelete(d *schema.ResourceData, meta interface{})
 		return err
 	}
 
+	err = resource.Retry(d.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
+		_, err := conn.Archon().InstanceGroups(namespace).Get(name)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return resource.NonRetryableError(err)
+		}
+
+		e := fmt.Errorf("InstanceGroup %s still exists", name)
+		return resource.RetryableError(e)
+	})
+	if err != nil {
+		objectMeta := metav1.ObjectMeta{Namespace: namespace, Name: name}
+		lastWarnings, wErr := getLastWarningsForObject(conn, objectMeta, "InstanceGroup", 3)
+		if wErr != nil {
+			return wErr
+		}
+		return fmt.Errorf("%s%s", err, stringifyEvents(lastWarnings))
+	}
+
 	log.Printf("[INFO] InstanceGroup %s deleted", name)
 
 	d.SetId("")
 	return nil
 }
 
-func resourceArchonInstanceGroupExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	conn := meta.(*archon.Clientset)
+// waitForInstanceGroup waits for the replicas of the group to converge.
+// On failure the error lists recent warnings of the group and of its
+// failing instances.
+func waitForInstanceGroup(conn *archon.Clientset, ns, name, waitFor string, timeout time.Duration) error {
+	err := resource.Retry(timeout, waitForDesiredReplicasFunc(conn, ns, name, waitFor))
+	if err != nil {
+		return instanceGroupWaitError(conn, ns, name, err)
+	}
+	return nil
+}
 
-	namespace, name := idParts(d.Id())
-	log.Printf("[INFO] Checking instance_group %s", name)
-	_, err := conn.Archon().InstanceGroups(namespace).Get(name)
+// instanceGroupWaitError appends recent warnings of the group and of its
+// failing instances to err
+func instanceGroupWaitError(conn *archon.Clientset, ns, name string, err error) error {
+	objectMeta := metav1.ObjectMeta{Namespace: ns, Name: name}
+	lastWarnings, wErr := getLastWarningsForObject(conn, objectMeta, "InstanceGroup", 3)
+	if wErr != nil {
+		return wErr
+	}
+
+	ig, wErr := conn.Archon().InstanceGroups(ns).Get(name)
+	if wErr != nil {
+		return wErr
+	}
+	instances, wErr := listInstancesBySelector(conn, ns, instanceGroupSelector(ig), "")
+	if wErr != nil {
+		return wErr
+	}
+	failing := failingInstances(instances)
+	if len(failing) > 3 {
+		failing = failing[:3]
+	}
+	for _, i := range failing {
+		warnings, wErr := getLastWarningsForObject(conn, i.ObjectMeta, "Instance", 3)
+		if wErr != nil {
+			return wErr
+		}
+		lastWarnings = append(lastWarnings, warnings...)
+	}
+
+	return fmt.Errorf("%s%s", err, stringifyObjectEvents(uniqueEvents(lastWarnings)))
+}
+
+// rollInstanceGroup replaces members not matching the template of the
+// group. Each batch optionally scales the group up by max_surge, deletes
+// up to max_surge + max_unavailable outdated instances and waits for the
+// controller to create their replacements from the current template.
+func rollInstanceGroup(conn *archon.Clientset, ns, name string, opts *rollingUpdateOptions, timeout time.Duration) error {
+	if opts.MaxUnavailable == 0 && opts.MaxSurge == 0 {
+		return fmt.Errorf("rolling_update: max_unavailable and max_surge can't both be 0")
+	}
+	waitFor := "scheduled"
+	if opts.WaitForReady {
+		waitFor = "ready"
+	}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		ig, err := conn.Archon().InstanceGroups(ns).Get(name)
+		if err != nil {
+			return err
+		}
+		instances, err := listInstancesBySelector(conn, ns, instanceGroupSelector(ig), "")
+		if err != nil {
+			return err
+		}
+		outdated := outdatedInstances(ownedInstances(instances, ig), &ig.Spec.Template)
+		if len(outdated) == 0 {
+			log.Printf("[INFO] All instances of %q match the template", name)
+			return nil
+		}
+
+		desired := ig.Spec.Replicas
+		surge := opts.MaxSurge
+		if int(surge) > len(outdated) {
+			surge = int32(len(outdated))
+		}
+		size := int(surge + opts.MaxUnavailable)
+		if size > len(outdated) {
+			size = len(outdated)
+		}
+		log.Printf("[INFO] Replacing %d of %d outdated instances of %q", size, len(outdated), name)
+
+		err = replaceOutdatedBatch(conn, ns, name, outdated[:size], desired, surge, waitFor, deadline)
+		if err != nil {
+			return err
+		}
+
+		if size < len(outdated) && opts.BatchPause > 0 {
+			log.Printf("[DEBUG] Pausing %s before the next batch of %q", opts.BatchPause, name)
+			time.Sleep(opts.BatchPause)
+		}
+	}
+}
+
+// replaceOutdatedBatch deletes the given outdated instances, surging the
+// group by surge replicas meanwhile, and waits for the replacements.
+// The group is scaled back to desired replicas even if the batch fails.
+func replaceOutdatedBatch(conn *archon.Clientset, ns, name string, outdated []cluster.Instance, desired, surge int32, waitFor string, deadline time.Time) error {
+	scaledUp := false
+	defer func() {
+		if !scaledUp {
+			return
+		}
+		err := scaleInstanceGroup(conn, ns, name, desired)
+		if err != nil {
+			log.Printf("[ERROR] Failed to scale instance_group %s back to %d replicas: %s", name, desired, err)
+		}
+	}()
+
+	if surge > 0 {
+		err := scaleInstanceGroup(conn, ns, name, desired+surge)
+		if err != nil {
+			return err
+		}
+		scaledUp = true
+		err = waitForRollingBatch(conn, ns, name, waitFor, deadline)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, i := range outdated {
+		log.Printf("[DEBUG] Deleting outdated instance %s", i.Name)
+		err := conn.Archon().Instances(ns).Delete(i.Name)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if scaledUp {
+		err := scaleInstanceGroup(conn, ns, name, desired)
+		if err != nil {
+			return err
+		}
+		scaledUp = false
+	}
+	return waitForRollingBatch(conn, ns, name, waitFor, deadline)
+}
+
+func scaleInstanceGroup(conn *archon.Clientset, ns, name string, replicas int32) error {
+	ops := PatchOperations{
+		&ReplaceOperation{
+			Path:  "/spec/replicas",
+			Value: replicas,
+		},
+	}
+	data, err := ops.MarshalJSON()
 	if err != nil {
-		if statusErr, ok := err.(*errors.StatusError); ok && statusErr.ErrStatus.Code == 404 {
-			return false, nil
+		return fmt.Errorf("Failed to marshal update operations: %s", err)
+	}
+
+	log.Printf("[INFO] Scaling instance_group %s to %d replicas", name, replicas)
+	_, err = conn.Archon().InstanceGroups(ns).Patch(name, pkgApi.JSONPatchType, data)
+	return err
+}
+
+// waitForRollingBatch waits for the group to converge like
+// waitForInstanceGroup, but gives up as soon as a replacement fails
+func waitForRollingBatch(conn *archon.Clientset, ns, name, waitFor string, deadline time.Time) error {
+	converged := waitForDesiredReplicasFunc(conn, ns, name, waitFor)
+	err := resource.Retry(deadline.Sub(time.Now()), func() *resource.RetryError {
+		ig, err := conn.Archon().InstanceGroups(ns).Get(name)
+		if err != nil {
+			return resource.NonRetryableError(err)
 		}
-		log.Printf("[DEBUG] Received error: %#v", err)
+		instances, err := listInstancesBySelector(conn, ns, instanceGroupSelector(ig), "")
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		for _, i := range ownedInstances(instances, ig) {
+			if !instanceMatchesTemplate(&i, &ig.Spec.Template) {
+				continue
+			}
+			if i.Status.Phase == cluster.InstanceFailed || i.Status.Phase == cluster.InstanceUnknown {
+				return resource.NonRetryableError(fmt.Errorf("Instance %s entered phase %s during rolling update of %q",
+					i.Name, i.Status.Phase, name))
+			}
+		}
+		return converged()
+	})
+	if err != nil {
+		return instanceGroupWaitError(conn, ns, name, err)
 	}
-	log.Printf("[INFO] InstanceGroup %s exists", name)
-	return true, err
+	return nil
 }
 
-func waitForDesiredReplicasFunc(conn *archon.Clientset, ns, name string) resource.RetryFunc {
+func waitForDesiredReplicasFunc(conn *archon.Clientset, ns, name, waitFor string) resource.RetryFunc {
 	return func() *resource.RetryError {
 		ig, err := conn.Archon().InstanceGroups(ns).Get(name)
 		if err != nil {
 			return resource.NonRetryableError(err)
 		}
 
-		desiredReplicas := ig.Spec.Replicas
-		log.Printf("[DEBUG] Current number of labelled replicas of %q: %d (of %d)\n",
-			ig.GetName(), ig.Status.FullyLabeledReplicas, desiredReplicas)
+		// Retrying won't help until whatever keeps the controller
+		// from creating instances is fixed
+		if c := getInstanceGroupReplicaFailure(ig.Status); c != nil {
+			return resource.NonRetryableError(fmt.Errorf("InstanceGroup %q failed to create replicas: %s: %s",
+				ig.GetName(), c.Reason, c.Message))
+		}
 
-		if ig.Status.FullyLabeledReplicas == desiredReplicas {
-			return nil
+		log.Printf("[DEBUG] Current replicas of %q: %#v (of %d)\n",
+			ig.GetName(), ig.Status, ig.Spec.Replicas)
+
+		err = instanceGroupReplicasConverged(ig, waitFor)
+		if err != nil {
+			return resource.RetryableError(err)
+		}
+
+		// After scaling down, surplus instances linger until their
+		// cloud resources are torn down
+		instances, err := listInstancesBySelector(conn, ns, instanceGroupSelector(ig), "")
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		if surplus := len(ownedInstances(instances, ig)) - int(ig.Spec.Replicas); surplus > 0 {
+			return resource.RetryableError(fmt.Errorf("Waiting for %d surplus instances of %q to be deleted",
+				surplus, ig.GetName()))
 		}
+		return nil
+	}
+}
+
+// instanceGroupReplicasConverged returns an error describing what is
+// still missing, or nil once the controller has observed the latest
+// generation of the group and the replicas counted by waitFor match
+// the desired number
+func instanceGroupReplicasConverged(ig *cluster.InstanceGroup, waitFor string) error {
+	if ig.Status.ObservedGeneration < ig.Generation {
+		return fmt.Errorf("Waiting for generation %d of %q to be observed (%d)",
+			ig.Generation, ig.GetName(), ig.Status.ObservedGeneration)
+	}
+
+	desiredReplicas := ig.Spec.Replicas
+	if ig.Status.Replicas != desiredReplicas {
+		return fmt.Errorf("Waiting for %q to scale to %d replicas (%d)",
+			ig.GetName(), desiredReplicas, ig.Status.Replicas)
+	}
 
-		return resource.RetryableError(fmt.Errorf("Waiting for %d replicas of %q to be scheduled (%d)",
-			desiredReplicas, ig.GetName(), ig.Status.FullyLabeledReplicas))
+	currentReplicas := ig.Status.FullyLabeledReplicas
+	switch waitFor {
+	case "ready":
+		currentReplicas = ig.Status.ReadyReplicas
+	case "available":
+		currentReplicas = ig.Status.AvailableReplicas
+	default:
+		waitFor = "scheduled"
 	}
+
+	if currentReplicas != desiredReplicas {
+		return fmt.Errorf("Waiting for %d replicas of %q to be %s (%d)",
+			desiredReplicas, ig.GetName(), waitFor, currentReplicas)
+	}
+	return nil
 }
@@ -19,12 +19,12 @@ func TestAccArchonInstanceGroup_basic(t *testing.T) {
 		PreCheck:      func() { testAccPreCheck(t) },
 		IDRefreshName: "archon_instancegroup.test",
 		Providers:     testAccProviders,
-		CheckDestroy:  testAccCheckArchonInstanceGroupDestroy,
+		CheckDestroy:  testAccCheckArchonDestroy("archon_instancegroup", testAccArchonInstanceGroupClient),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccArchonInstanceGroupConfig_basic(name),
 				Check: resource.ComposeAggregateTestCheckFunc(
-					testAccCheckArchonInstanceGroupExists("archon_instancegroup.test", &conf),
+					testAccCheckArchonExists("archon_instancegroup.test", testAccArchonInstanceGroupClient, &conf),
 					resource.TestCheckResourceAttr("archon_instancegroup.test", "metadata.0.annotations.%", "2"),
 					resource.TestCheckResourceAttr("archon_instancegroup.test", "metadata.0.annotations.TestAnnotationOne", "one"),
 					resource.TestCheckResourceAttr("archon_instancegroup.test", "metadata.0.annotations.TestAnnotationTwo", "two"),
@@ -41,12 +41,19 @@ func TestAccArchonInstanceGroup_basic(t *testing.T) {
 					resource.TestCheckResourceAttrSet("archon_instancegroup.test", "metadata.0.uid"),
 					resource.TestCheckResourceAttr("archon_instancegroup.test", "spec.#", "1"),
 					resource.TestCheckResourceAttr("archon_instancegroup.test", "spec.0.replicas", "2"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "status.#", "1"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "status.0.replicas", "2"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "status.0.fully_labeled_replicas", "2"),
+					resource.TestCheckResourceAttrSet("archon_instancegroup.test", "status.0.observed_generation"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "instances.#", "2"),
+					resource.TestCheckResourceAttrSet("archon_instancegroup.test", "instances.0.name"),
+					resource.TestCheckResourceAttrSet("archon_instancegroup.test", "instances.0.phase"),
 				),
 			},
 			{
 				Config: testAccArchonInstanceGroupConfig_modified(name),
 				Check: resource.ComposeAggregateTestCheckFunc(
-					testAccCheckArchonInstanceGroupExists("archon_instancegroup.test", &conf),
+					testAccCheckArchonExists("archon_instancegroup.test", testAccArchonInstanceGroupClient, &conf),
 					resource.TestCheckResourceAttr("archon_instancegroup.test", "metadata.0.annotations.%", "2"),
 					resource.TestCheckResourceAttr("archon_instancegroup.test", "metadata.0.annotations.TestAnnotationOne", "one"),
 					resource.TestCheckResourceAttr("archon_instancegroup.test", "metadata.0.annotations.Different", "1234"),
@@ -68,66 +75,254 @@ func TestAccArchonInstanceGroup_basic(t *testing.T) {
 	})
 }
 
-func TestAccArchonInstanceGroup_importBasic(t *testing.T) {
-	resourceName := "archon_instancegroup.test"
+func TestAccArchonInstanceGroup_generatedName(t *testing.T) {
+	testAccArchonGeneratedNameTest(t, "archon_instancegroup", testAccArchonInstanceGroupClient, testAccArchonInstanceGroupConfig_generatedName)
+}
+
+func TestAccArchonInstanceGroup_waitForAvailable(t *testing.T) {
+	var conf cluster.InstanceGroup
 	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
 
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
 		Providers:    testAccProviders,
-		CheckDestroy: testAccCheckArchonInstanceGroupDestroy,
+		CheckDestroy: testAccCheckArchonDestroy("archon_instancegroup", testAccArchonInstanceGroupClient),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccArchonInstanceGroupConfig_basic(name),
+				Config: testAccArchonInstanceGroupConfig_waitForAvailable(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckArchonExists("archon_instancegroup.test", testAccArchonInstanceGroupClient, &conf),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "wait_for", "available"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "spec.0.min_ready_seconds", "10"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "status.0.ready_replicas", "2"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "status.0.available_replicas", "2"),
+				),
 			},
+		},
+	})
+}
+
+func TestAccArchonInstanceGroup_scale(t *testing.T) {
+	var conf cluster.InstanceGroup
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckArchonDestroy("archon_instancegroup", testAccArchonInstanceGroupClient),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccArchonInstanceGroupConfig_scale(name, 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckArchonExists("archon_instancegroup.test", testAccArchonInstanceGroupClient, &conf),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "status.0.replicas", "1"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "status.0.ready_replicas", "1"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "instances.#", "1"),
+				),
+			},
+			{
+				Config: testAccArchonInstanceGroupConfig_scale(name, 3),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckArchonExists("archon_instancegroup.test", testAccArchonInstanceGroupClient, &conf),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "status.0.replicas", "3"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "status.0.ready_replicas", "3"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "instances.#", "3"),
+				),
+			},
+			{
+				Config: testAccArchonInstanceGroupConfig_scale(name, 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckArchonExists("archon_instancegroup.test", testAccArchonInstanceGroupClient, &conf),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "status.0.replicas", "1"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "status.0.ready_replicas", "1"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "instances.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccArchonInstanceGroup_updateTemplate(t *testing.T) {
+	var before, after cluster.InstanceGroup
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
 
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckArchonDestroy("archon_instancegroup", testAccArchonInstanceGroupClient),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccArchonInstanceGroupConfig_template(name, "first", "one"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckArchonExists("archon_instancegroup.test", testAccArchonInstanceGroupClient, &before),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "spec.0.template.0.metadata.0.labels.%", "2"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "spec.0.template.0.metadata.0.labels.version", "one"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "spec.0.template.0.spec.0.image", "first"),
+				),
+			},
 			{
-				ResourceName:      resourceName,
-				ImportState:       true,
-				ImportStateVerify: true,
+				Config: testAccArchonInstanceGroupConfig_template(name, "second", "two"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckArchonExists("archon_instancegroup.test", testAccArchonInstanceGroupClient, &after),
+					testAccCheckArchonInstanceGroupNotRecreated(&before, &after),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "spec.0.template.0.metadata.0.labels.%", "2"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "spec.0.template.0.metadata.0.labels.version", "two"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "spec.0.template.0.spec.0.image", "second"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "spec.0.replicas", "2"),
+				),
 			},
 		},
 	})
 }
 
-func testAccCheckArchonInstanceGroupDestroy(s *terraform.State) error {
-	conn := testAccProvider.Meta().(*archon.Clientset)
+func TestAccArchonInstanceGroup_rollingUpdate(t *testing.T) {
+	var before, after cluster.InstanceGroup
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
 
-	for _, rs := range s.RootModule().Resources {
-		if rs.Type != "archon_instancegroup" {
-			continue
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckArchonDestroy("archon_instancegroup", testAccArchonInstanceGroupClient),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccArchonInstanceGroupConfig_rollingUpdate(name, "first"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckArchonExists("archon_instancegroup.test", testAccArchonInstanceGroupClient, &before),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "rolling_update.#", "1"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "rolling_update.0.max_unavailable", "1"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "rolling_update.0.max_surge", "1"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "rolling_update.0.batch_pause", "5s"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "rolling_update.0.wait_for_ready", "true"),
+				),
+			},
+			{
+				Config: testAccArchonInstanceGroupConfig_rollingUpdate(name, "second"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckArchonExists("archon_instancegroup.test", testAccArchonInstanceGroupClient, &after),
+					testAccCheckArchonInstanceGroupNotRecreated(&before, &after),
+					testAccCheckArchonInstanceGroupMembersUpToDate(&after),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "spec.0.template.0.spec.0.image", "second"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "status.0.replicas", "3"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "status.0.ready_replicas", "3"),
+					resource.TestCheckResourceAttr("archon_instancegroup.test", "instances.#", "3"),
+				),
+			},
+		},
+	})
+}
+
+func TestInstanceGroupReplicasConverged(t *testing.T) {
+	group := func(generation, observed int64, replicas, labeled, ready, available int32) *cluster.InstanceGroup {
+		ig := &cluster.InstanceGroup{}
+		ig.Name = "test"
+		ig.Generation = generation
+		ig.Spec.Replicas = replicas
+		ig.Status = cluster.InstanceGroupStatus{
+			Replicas:             replicas,
+			FullyLabeledReplicas: labeled,
+			ReadyReplicas:        ready,
+			AvailableReplicas:    available,
+			ObservedGeneration:   observed,
 		}
-		namespace, name := idParts(rs.Primary.ID)
-		resp, err := conn.Archon().InstanceGroups(namespace).Get(name)
-		if err == nil {
-			if resp.Name == rs.Primary.ID {
-				return fmt.Errorf("InstanceGroup still exists: %s", rs.Primary.ID)
+		return ig
+	}
+
+	// Scaled down from 3 to 1 with the surplus instances still around
+	scaledDown := group(2, 2, 1, 1, 1, 1)
+	scaledDown.Status.Replicas = 3
+
+	testCases := []struct {
+		Group     *cluster.InstanceGroup
+		WaitFor   string
+		Converged bool
+	}{
+		{group(2, 1, 3, 3, 3, 3), "scheduled", false},
+		{group(2, 2, 3, 2, 0, 0), "scheduled", false},
+		{group(2, 2, 3, 3, 0, 0), "scheduled", true},
+		{group(2, 2, 3, 3, 2, 0), "ready", false},
+		{group(2, 2, 3, 3, 3, 0), "ready", true},
+		{group(2, 2, 3, 3, 3, 2), "available", false},
+		{group(2, 3, 3, 3, 3, 3), "available", true},
+		{scaledDown, "available", false},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			err := instanceGroupReplicasConverged(tc.Group, tc.WaitFor)
+			if tc.Converged && err != nil {
+				t.Fatalf("Expected %#v to be converged: %s", tc.Group.Status, err)
 			}
-		}
+			if !tc.Converged && err == nil {
+				t.Fatalf("Expected %#v not to be converged", tc.Group.Status)
+			}
+		})
 	}
+}
+
+func TestAccArchonInstanceGroup_importBasic(t *testing.T) {
+	resourceName := "archon_instancegroup.test"
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckArchonDestroy("archon_instancegroup", testAccArchonInstanceGroupClient),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccArchonInstanceGroupConfig_basic(name),
+			},
 
-	return nil
+			{
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"wait_for"},
+			},
+		},
+	})
 }
 
-func testAccCheckArchonInstanceGroupExists(n string, obj *cluster.InstanceGroup) resource.TestCheckFunc {
+func TestAccArchonInstanceGroup_disappears(t *testing.T) {
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+	testAccArchonDisappearsTest(t, "archon_instancegroup", testAccArchonInstanceGroupClient, testAccArchonInstanceGroupConfig_basic(name))
+}
+
+func testAccCheckArchonInstanceGroupNotRecreated(before, after *cluster.InstanceGroup) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		rs, ok := s.RootModule().Resources[n]
-		if !ok {
-			return fmt.Errorf("Not found: %s", n)
+		if before.UID != after.UID {
+			return fmt.Errorf("InstanceGroup %s was recreated: UID changed from %s to %s",
+				after.Name, before.UID, after.UID)
 		}
+		return nil
+	}
+}
 
+func testAccCheckArchonInstanceGroupMembersUpToDate(obj *cluster.InstanceGroup) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
 		conn := testAccProvider.Meta().(*archon.Clientset)
-		namespace, name := idParts(rs.Primary.ID)
-		out, err := conn.Archon().InstanceGroups(namespace).Get(name)
+		instances, err := listInstancesBySelector(conn, obj.Namespace, instanceGroupSelector(obj), "")
 		if err != nil {
 			return err
 		}
-
-		*obj = *out
+		outdated := outdatedInstances(instances, &obj.Spec.Template)
+		if len(outdated) > 0 {
+			return fmt.Errorf("%d of %d instances of %s don't match the template",
+				len(outdated), len(instances), obj.Name)
+		}
 		return nil
 	}
 }
 
+func testAccArchonInstanceGroupClient(conn *archon.Clientset) testAccArchonClient {
+	return testAccArchonClient{
+		Get: func(namespace, name string) (interface{}, error) {
+			return conn.Archon().InstanceGroups(namespace).Get(name)
+		},
+		Delete: func(namespace, name string) error {
+			return conn.Archon().InstanceGroups(namespace).Delete(name)
+		},
+	}
+}
+
 func testAccArchonInstanceGroupConfig_basic(name string) string {
 	return fmt.Sprintf(`
 resource "archon_instancegroup" "test" {
@@ -242,3 +437,213 @@ resource "archon_network" "test" {
 	}
 }`, name)
 }
+
+func testAccArchonInstanceGroupConfig_generatedName(prefix string) string {
+	return fmt.Sprintf(`
+resource "archon_instancegroup" "test" {
+	metadata {
+		generate_name = "%s"
+	}
+	spec {
+		replicas = 1
+		selector {
+			match_labels {
+				app = "test-generated"
+			}
+		}
+		template {
+			metadata {
+				labels {
+					app = "test-generated"
+				}
+			}
+			spec {
+				image = "first"
+				os = "second"
+				network_name = "${archon_network.test.metadata.0.name}"
+			}
+		}
+	}
+}
+
+resource "archon_network" "test" {
+	metadata {
+		name = "tf-acc-network"
+	}
+	spec {
+		region = "first"
+		zone = "second"
+		subnet = "10.0.0.0/24"
+	}
+}`, prefix)
+}
+
+func testAccArchonInstanceGroupConfig_waitForAvailable(name string) string {
+	return fmt.Sprintf(`
+resource "archon_instancegroup" "test" {
+	metadata {
+		name = "%s"
+	}
+	spec {
+		replicas = 2
+		min_ready_seconds = 10
+		selector {
+			match_labels {
+				app = "test-available"
+			}
+		}
+		template {
+			metadata {
+				labels {
+					app = "test-available"
+				}
+			}
+			spec {
+				image = "first"
+				os = "second"
+				network_name = "${archon_network.test.metadata.0.name}"
+			}
+		}
+	}
+	wait_for = "available"
+}
+
+resource "archon_network" "test" {
+	metadata {
+		name = "tf-acc-network"
+	}
+	spec {
+		region = "first"
+		zone = "second"
+		subnet = "10.0.0.0/24"
+	}
+}`, name)
+}
+
+func testAccArchonInstanceGroupConfig_scale(name string, replicas int) string {
+	return fmt.Sprintf(`
+resource "archon_instancegroup" "test" {
+	metadata {
+		name = "%s"
+	}
+	spec {
+		replicas = %d
+		selector {
+			match_labels {
+				app = "test-scale"
+			}
+		}
+		template {
+			metadata {
+				labels {
+					app = "test-scale"
+				}
+			}
+			spec {
+				image = "first"
+				os = "second"
+				network_name = "${archon_network.test.metadata.0.name}"
+			}
+		}
+	}
+	wait_for = "ready"
+}
+
+resource "archon_network" "test" {
+	metadata {
+		name = "tf-acc-network"
+	}
+	spec {
+		region = "first"
+		zone = "second"
+		subnet = "10.0.0.0/24"
+	}
+}`, name, replicas)
+}
+
+func testAccArchonInstanceGroupConfig_template(name, image, version string) string {
+	return fmt.Sprintf(`
+resource "archon_instancegroup" "test" {
+	metadata {
+		name = "%s"
+	}
+	spec {
+		replicas = 2
+		selector {
+			match_labels {
+				app = "test-template"
+			}
+		}
+		template {
+			metadata {
+				labels {
+					app = "test-template"
+					version = "%s"
+				}
+			}
+			spec {
+				image = "%s"
+				os = "second"
+				network_name = "${archon_network.test.metadata.0.name}"
+			}
+		}
+	}
+}
+
+resource "archon_network" "test" {
+	metadata {
+		name = "tf-acc-network"
+	}
+	spec {
+		region = "first"
+		zone = "second"
+		subnet = "10.0.0.0/24"
+	}
+}`, name, version, image)
+}
+
+func testAccArchonInstanceGroupConfig_rollingUpdate(name, image string) string {
+	return fmt.Sprintf(`
+resource "archon_instancegroup" "test" {
+	metadata {
+		name = "%s"
+	}
+	spec {
+		replicas = 3
+		selector {
+			match_labels {
+				app = "test-rolling"
+			}
+		}
+		template {
+			metadata {
+				labels {
+					app = "test-rolling"
+				}
+			}
+			spec {
+				image = "%s"
+				os = "second"
+				network_name = "${archon_network.test.metadata.0.name}"
+			}
+		}
+	}
+	wait_for = "ready"
+	rolling_update {
+		max_unavailable = 1
+		max_surge = 1
+		batch_pause = "5s"
+	}
+}
+
+resource "archon_network" "test" {
+	metadata {
+		name = "tf-acc-network"
+	}
+	spec {
+		region = "first"
+		zone = "second"
+		subnet = "10.0.0.0/24"
+	}
+}`, name, image)
+}
@@ -2,11 +2,14 @@ package kubernetes
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	archon "kubeup.com/archon/pkg/clientset"
 	"kubeup.com/archon/pkg/cluster"
 )
@@ -19,12 +22,12 @@ func TestAccArchonInstance_basic(t *testing.T) {
 		PreCheck:      func() { testAccPreCheck(t) },
 		IDRefreshName: "archon_instance.test",
 		Providers:     testAccProviders,
-		CheckDestroy:  testAccCheckArchonInstanceDestroy,
+		CheckDestroy:  testAccCheckArchonDestroy("archon_instance", testAccArchonInstanceClient),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccArchonInstanceConfig_basic(name),
 				Check: resource.ComposeAggregateTestCheckFunc(
-					testAccCheckArchonInstanceExists("archon_instance.test", &conf),
+					testAccCheckArchonExists("archon_instance.test", testAccArchonInstanceClient, &conf),
 					resource.TestCheckResourceAttr("archon_instance.test", "metadata.0.annotations.%", "2"),
 					resource.TestCheckResourceAttr("archon_instance.test", "metadata.0.annotations.TestAnnotationOne", "one"),
 					resource.TestCheckResourceAttr("archon_instance.test", "metadata.0.annotations.TestAnnotationTwo", "two"),
@@ -43,12 +46,17 @@ func TestAccArchonInstance_basic(t *testing.T) {
 					resource.TestCheckResourceAttr("archon_instance.test", "spec.0.image", "first"),
 					resource.TestCheckResourceAttr("archon_instance.test", "spec.0.os", "second"),
 					resource.TestCheckResourceAttr("archon_instance.test", "spec.0.network_name", "tf-acc-network"),
+					resource.TestCheckResourceAttr("archon_instance.test", "status.#", "1"),
+					resource.TestCheckResourceAttr("archon_instance.test", "status.0.phase", "Running"),
+					resource.TestCheckResourceAttrSet("archon_instance.test", "status.0.instance_id"),
+					resource.TestCheckResourceAttrSet("archon_instance.test", "status.0.private_ip"),
+					resource.TestCheckResourceAttrSet("archon_instance.test", "status.0.conditions.#"),
 				),
 			},
 			{
 				Config: testAccArchonInstanceConfig_modified(name),
 				Check: resource.ComposeAggregateTestCheckFunc(
-					testAccCheckArchonInstanceExists("archon_instance.test", &conf),
+					testAccCheckArchonExists("archon_instance.test", testAccArchonInstanceClient, &conf),
 					resource.TestCheckResourceAttr("archon_instance.test", "metadata.0.annotations.%", "2"),
 					resource.TestCheckResourceAttr("archon_instance.test", "metadata.0.annotations.TestAnnotationOne", "one"),
 					resource.TestCheckResourceAttr("archon_instance.test", "metadata.0.annotations.Different", "1234"),
@@ -72,6 +80,102 @@ func TestAccArchonInstance_basic(t *testing.T) {
 	})
 }
 
+func TestAccArchonInstance_generatedName(t *testing.T) {
+	testAccArchonGeneratedNameTest(t, "archon_instance", testAccArchonInstanceClient, testAccArchonInstanceConfig_generatedName)
+}
+
+func TestAccArchonInstance_waitForAvailable(t *testing.T) {
+	var conf cluster.Instance
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckArchonDestroy("archon_instance", testAccArchonInstanceClient),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccArchonInstanceConfig_waitForAvailable(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckArchonExists("archon_instance.test", testAccArchonInstanceClient, &conf),
+					testAccCheckArchonInstanceAvailable(&conf, 10),
+					resource.TestCheckResourceAttr("archon_instance.test", "wait_for", "available"),
+					resource.TestCheckResourceAttr("archon_instance.test", "min_ready_seconds", "10"),
+					resource.TestCheckResourceAttr("archon_instance.test", "status.0.phase", "Running"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccArchonInstance_autoEncodedFile(t *testing.T) {
+	var conf cluster.Instance
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckArchonDestroy("archon_instance", testAccArchonInstanceClient),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccArchonInstanceConfig_autoEncodedFile(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckArchonExists("archon_instance.test", testAccArchonInstanceClient, &conf),
+					resource.TestCheckResourceAttr("archon_instance.test", "spec.0.files.#", "1"),
+					resource.TestCheckResourceAttr("archon_instance.test", "spec.0.files.0.content_encoding", "auto"),
+					resource.TestCheckResourceAttr("archon_instance.test", "spec.0.files.0.content", fileContentHash("hello world\n")),
+					testAccCheckArchonInstanceFileContent(&conf, 0, "hello world\n"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccArchonInstance_waitsForNetwork(t *testing.T) {
+	var conf cluster.Instance
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+	networkName := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		CheckDestroy: func(s *terraform.State) error {
+			conn := testAccProvider.Meta().(*archon.Clientset)
+			err := conn.Archon().Networks("default").Delete(networkName)
+			if err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			return testAccCheckArchonDestroy("archon_instance", testAccArchonInstanceClient)(s)
+		},
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { testAccCreateArchonNetwork(t, networkName) },
+				Config:    testAccArchonInstanceConfig_networkName(name, networkName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckArchonExists("archon_instance.test", testAccArchonInstanceClient, &conf),
+					resource.TestCheckResourceAttr("archon_instance.test", "spec.0.network_name", networkName),
+					resource.TestCheckResourceAttr("archon_instance.test", "status.0.phase", "Running"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccArchonInstance_missingReferences(t *testing.T) {
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckArchonDestroy("archon_instance", testAccArchonInstanceClient),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccArchonInstanceConfig_missingReferences(name),
+				ExpectError: regexp.MustCompile(`(?s)network "tf-acc-missing-network" not found.*user "tf-acc-missing-user" not found.*secret "tf-acc-missing-secret" not found.*reserved instance "tf-acc-missing-reserved" not found`),
+			},
+		},
+	})
+}
+
 func TestAccArchonInstance_importBasic(t *testing.T) {
 	resourceName := "archon_instance.test"
 	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
@@ -79,59 +183,85 @@ func TestAccArchonInstance_importBasic(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
 		Providers:    testAccProviders,
-		CheckDestroy: testAccCheckArchonInstanceDestroy,
+		CheckDestroy: testAccCheckArchonDestroy("archon_instance", testAccArchonInstanceClient),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccArchonInstanceConfig_basic(name),
 			},
 
 			{
-				ResourceName:      resourceName,
-				ImportState:       true,
-				ImportStateVerify: true,
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"wait_for", "min_ready_seconds"},
 			},
 		},
 	})
 }
 
-func testAccCheckArchonInstanceDestroy(s *terraform.State) error {
-	conn := testAccProvider.Meta().(*archon.Clientset)
+func TestAccArchonInstance_disappears(t *testing.T) {
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+	testAccArchonDisappearsTest(t, "archon_instance", testAccArchonInstanceClient, testAccArchonInstanceConfig_basic(name))
+}
 
-	for _, rs := range s.RootModule().Resources {
-		if rs.Type != "archon_instance" {
-			continue
-		}
-		namespace, name := idParts(rs.Primary.ID)
-		resp, err := conn.Archon().Instances(namespace).Get(name)
-		if err == nil {
-			if resp.Name == rs.Primary.ID {
-				return fmt.Errorf("Instance still exists: %s", rs.Primary.ID)
-			}
-		}
+// testAccCreateArchonNetwork creates a network outside of Terraform
+// without waiting for it, so it is still Pending when the config is applied
+func testAccCreateArchonNetwork(t *testing.T, name string) {
+	conn := testAccProvider.Meta().(*archon.Clientset)
+	network := cluster.Network{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: cluster.NetworkSpec{
+			Region: "first",
+			Zone:   "second",
+			Subnet: "10.0.0.0/24",
+		},
+	}
+	_, err := conn.Archon().Networks(network.Namespace).Create(&network)
+	if err != nil {
+		t.Fatalf("Failed to create network %s: %s", name, err)
 	}
-
-	return nil
 }
 
-func testAccCheckArchonInstanceExists(n string, obj *cluster.Instance) resource.TestCheckFunc {
+func testAccCheckArchonInstanceFileContent(obj *cluster.Instance, i int, plaintext string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		rs, ok := s.RootModule().Resources[n]
-		if !ok {
-			return fmt.Errorf("Not found: %s", n)
+		if len(obj.Spec.Files) <= i {
+			return fmt.Errorf("Expected at least %d files, got %d", i+1, len(obj.Spec.Files))
 		}
-
-		conn := testAccProvider.Meta().(*archon.Clientset)
-		namespace, name := idParts(rs.Primary.ID)
-		out, err := conn.Archon().Instances(namespace).Get(name)
+		file := obj.Spec.Files[i]
+		if file.Encoding != autoFileEncoding {
+			return fmt.Errorf("Expected file encoding %q, got %q", autoFileEncoding, file.Encoding)
+		}
+		decoded, err := decodeFileContent(file.Content)
 		if err != nil {
 			return err
 		}
+		if decoded != plaintext {
+			return fmt.Errorf("Expected file content %q, got %q", plaintext, decoded)
+		}
+		return nil
+	}
+}
 
-		*obj = *out
+func testAccCheckArchonInstanceAvailable(obj *cluster.Instance, minReadySeconds int32) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if !cluster.IsInstanceAvailable(obj, minReadySeconds, metav1.Now()) {
+			return fmt.Errorf("Instance %s is not available: %#v", obj.Name, obj.Status.Conditions)
+		}
 		return nil
 	}
 }
 
+func testAccArchonInstanceClient(conn *archon.Clientset) testAccArchonClient {
+	return testAccArchonClient{
+		Get: func(namespace, name string) (interface{}, error) {
+			return conn.Archon().Instances(namespace).Get(name)
+		},
+		Delete: func(namespace, name string) error {
+			return conn.Archon().Instances(namespace).Delete(name)
+		},
+	}
+}
+
 func testAccArchonInstanceConfig_basic(name string) string {
 	return fmt.Sprintf(`
 resource "archon_instance" "test" {
@@ -216,3 +346,123 @@ resource "archon_network" "test" {
 	}
 }`, name)
 }
+
+func testAccArchonInstanceConfig_generatedName(prefix string) string {
+	return fmt.Sprintf(`
+resource "archon_instance" "test" {
+	metadata {
+		generate_name = "%s"
+	}
+	spec {
+		image = "first"
+		os = "second"
+		network_name = "${archon_network.test.metadata.0.name}"
+	}
+}
+
+resource "archon_network" "test" {
+	metadata {
+		name = "tf-acc-network"
+	}
+	spec {
+		region = "first"
+		zone = "second"
+		subnet = "10.0.0.0/24"
+	}
+}`, prefix)
+}
+
+func testAccArchonInstanceConfig_waitForAvailable(name string) string {
+	return fmt.Sprintf(`
+resource "archon_instance" "test" {
+	metadata {
+		name = "%s"
+	}
+	spec {
+		image = "first"
+		os = "second"
+		network_name = "${archon_network.test.metadata.0.name}"
+	}
+	wait_for = "available"
+	min_ready_seconds = 10
+}
+
+resource "archon_network" "test" {
+	metadata {
+		name = "tf-acc-network"
+	}
+	spec {
+		region = "first"
+		zone = "second"
+		subnet = "10.0.0.0/24"
+	}
+}`, name)
+}
+
+func testAccArchonInstanceConfig_autoEncodedFile(name string) string {
+	return fmt.Sprintf(`
+resource "archon_instance" "test" {
+	metadata {
+		name = "%s"
+	}
+	spec {
+		image = "first"
+		os = "second"
+		network_name = "${archon_network.test.metadata.0.name}"
+		files {
+			name = "motd"
+			path = "/etc/motd"
+			content = "hello world\n"
+			content_encoding = "auto"
+		}
+	}
+}
+
+resource "archon_network" "test" {
+	metadata {
+		name = "tf-acc-network"
+	}
+	spec {
+		region = "first"
+		zone = "second"
+		subnet = "10.0.0.0/24"
+	}
+}`, name)
+}
+
+func testAccArchonInstanceConfig_missingReferences(name string) string {
+	return fmt.Sprintf(`
+resource "archon_instance" "test" {
+	metadata {
+		name = "%s"
+	}
+	spec {
+		image = "first"
+		os = "second"
+		network_name = "tf-acc-missing-network"
+		users {
+			name = "tf-acc-missing-user"
+		}
+		secrets {
+			name = "tf-acc-missing-secret"
+		}
+		reserved_instance_ref {
+			name = "tf-acc-missing-reserved"
+		}
+	}
+}`, name)
+}
+
+func testAccArchonInstanceConfig_networkName(name, networkName string) string {
+	return fmt.Sprintf(`
+resource "archon_instance" "test" {
+	metadata {
+		name = "%s"
+	}
+	spec {
+		image = "first"
+		os = "second"
+		network_name = "%s"
+	}
+}`, name, networkName)
+}
@@ -3,11 +3,13 @@ package kubernetes
 import (
 	"fmt"
 	"log"
+	"sort"
 	"time"
 
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	pkgApi "k8s.io/apimachinery/pkg/types"
 	archon "kubeup.com/archon/pkg/clientset"
 	"kubeup.com/archon/pkg/cluster"
@@ -17,12 +19,15 @@ func resourceArchonNetwork() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceArchonNetworkCreate,
 		Read:   resourceArchonNetworkRead,
-		Exists: resourceArchonNetworkExists,
 		Update: resourceArchonNetworkUpdate,
 		Delete: resourceArchonNetworkDelete,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"metadata": namespacedMetadataSchema("network", true),
@@ -51,6 +56,12 @@ func resourceArchonNetwork() *schema.Resource {
 					},
 				},
 			},
+			"force_destroy": {
+				Type:        schema.TypeBool,
+				Description: "Delete the network even if instances or instance groups still refer to it.",
+				Optional:    true,
+				Default:     false,
+			},
 		},
 	}
 }
@@ -71,29 +82,10 @@ func resourceArchonNetworkCreate(d *schema.ResourceData, meta interface{}) error
 	log.Printf("[INFO] Submitted new network: %#v", out)
 	d.SetId(buildId(out.ObjectMeta))
 
-	stateConf := &resource.StateChangeConf{
-		Target:  []string{"Running"},
-		Pending: []string{"Pending"},
-		Timeout: 5 * time.Minute,
-		Refresh: func() (interface{}, string, error) {
-			out, err := conn.Archon().Networks(metadata.Namespace).Get(metadata.Name)
-			if err != nil {
-				log.Printf("[ERROR] Received error: %#v", err)
-				return out, "Error", err
-			}
-
-			statusPhase := fmt.Sprintf("%v", out.Status.Phase)
-			log.Printf("[DEBUG] Network %s status received: %#v", out.Name, statusPhase)
-			return out, statusPhase, nil
-		},
-	}
-	_, err = stateConf.WaitForState()
+	// Name may have been generated by the server
+	err = waitForNetwork(conn, out.Namespace, out.Name, d.Timeout(schema.TimeoutCreate))
 	if err != nil {
-		lastWarnings, wErr := getLastWarningsForObject(conn, out.ObjectMeta, "Network", 3)
-		if wErr != nil {
-			return wErr
-		}
-		return fmt.Errorf("%s%s", err, stringifyEvents(lastWarnings))
+		return err
 	}
 	log.Printf("[INFO] Network %s created", out.Name)
 
@@ -107,6 +99,11 @@ func resourceArchonNetworkRead(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[INFO] Reading network %s", name)
 	network, err := conn.Archon().Networks(namespace).Get(name)
 	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Printf("[WARN] Network %s not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
 		log.Printf("[DEBUG] Received error: %#v", err)
 		return err
 	}
@@ -156,30 +153,130 @@ func resourceArchonNetworkDelete(d *schema.ResourceData, meta interface{}) error
 	conn := meta.(*archon.Clientset)
 
 	namespace, name := idParts(d.Id())
+	if !d.Get("force_destroy").(bool) {
+		dependents, err := listNetworkDependents(conn, namespace, name)
+		if err != nil {
+			return err
+		}
+		if len(dependents) > 0 {
+			var output string
+			for _, dep := range dependents {
+				output += fmt.Sprintf("\n   * %s", dep)
+			}
+			return fmt.Errorf("Network %s is still in use by:%s\n\nSet force_destroy to delete it anyway", d.Id(), output)
+		}
+	}
+
 	log.Printf("[INFO] Deleting network: %#v", name)
 	err := conn.Archon().Networks(namespace).Delete(name)
 	if err != nil {
 		return err
 	}
 
+	err = resource.Retry(d.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
+		_, err := conn.Archon().Networks(namespace).Get(name)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return resource.NonRetryableError(err)
+		}
+
+		e := fmt.Errorf("Network %s still exists", name)
+		return resource.RetryableError(e)
+	})
+	if err != nil {
+		objectMeta := metav1.ObjectMeta{Namespace: namespace, Name: name}
+		lastWarnings, wErr := getLastWarningsForObject(conn, objectMeta, "Network", 3)
+		if wErr != nil {
+			return wErr
+		}
+		return fmt.Errorf("%s%s", err, stringifyEvents(lastWarnings))
+	}
+
 	log.Printf("[INFO] Network %s deleted", name)
 
 	d.SetId("")
 	return nil
 }
 
-func resourceArchonNetworkExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	conn := meta.(*archon.Clientset)
+// waitForNetwork waits for the network to be Running. On failure the
+// error lists recent warnings of the network.
+func waitForNetwork(conn *archon.Clientset, namespace, name string, timeout time.Duration) error {
+	stateConf := &resource.StateChangeConf{
+		Target:  []string{"Running"},
+		Pending: []string{"Pending"},
+		Timeout: timeout,
+		Refresh: func() (interface{}, string, error) {
+			out, err := conn.Archon().Networks(namespace).Get(name)
+			if err != nil {
+				log.Printf("[ERROR] Received error: %#v", err)
+				return out, "Error", err
+			}
 
-	namespace, name := idParts(d.Id())
-	log.Printf("[INFO] Checking network %s", name)
-	_, err := conn.Archon().Networks(namespace).Get(name)
+			statusPhase := fmt.Sprintf("%v", out.Status.Phase)
+			log.Printf("[DEBUG] Network %s status received: %#v", out.Name, statusPhase)
+
+			phase := out.Status.Phase
+			if phase == cluster.NetworkFailed || phase == cluster.NetworkUnknown {
+				return out, statusPhase, fmt.Errorf("Network %s entered phase %s", out.Name, phase)
+			}
+			return out, statusPhase, nil
+		},
+	}
+	_, err := stateConf.WaitForState()
 	if err != nil {
-		if statusErr, ok := err.(*errors.StatusError); ok && statusErr.ErrStatus.Code == 404 {
-			return false, nil
+		objectMeta := metav1.ObjectMeta{Namespace: namespace, Name: name}
+		lastWarnings, wErr := getLastWarningsForObject(conn, objectMeta, "Network", 3)
+		if wErr != nil {
+			return wErr
 		}
-		log.Printf("[DEBUG] Received error: %#v", err)
+		return fmt.Errorf("%s%s", err, stringifyEvents(lastWarnings))
+	}
+	return nil
+}
+
+// listNetworkDependents returns the instance groups and instances in the
+// namespace which refer to the network, sorted. Objects being deleted are
+// left out, as are instances of a group which is listed or being deleted.
+// Instances whose group is gone are still reported.
+func listNetworkDependents(conn *archon.Clientset, namespace, name string) ([]string, error) {
+	groups, err := conn.Archon().InstanceGroups(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	instances, err := conn.Archon().Instances(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
 	}
-	log.Printf("[INFO] Network %s exists", name)
-	return true, err
+	return networkDependents(name, groups.Items, instances.Items), nil
+}
+
+func networkDependents(name string, groups []cluster.InstanceGroup, instances []cluster.Instance) []string {
+	var dependents []string
+	// Groups whose instances are already accounted for
+	coveredGroups := make(map[string]bool)
+	for _, g := range groups {
+		if g.DeletionTimestamp != nil {
+			// Its instances are being deleted along with it
+			coveredGroups[g.Name] = true
+			continue
+		}
+		if g.Spec.Template.Spec.NetworkName == name {
+			coveredGroups[g.Name] = true
+			dependents = append(dependents, fmt.Sprintf("instance group %q", g.Name))
+		}
+	}
+	for _, i := range instances {
+		if i.DeletionTimestamp != nil || i.Spec.NetworkName != name {
+			continue
+		}
+		if owner := instanceGroupOwner(&i); owner != "" && coveredGroups[owner] {
+			continue
+		}
+		dependents = append(dependents, fmt.Sprintf("instance %q", i.Name))
+	}
+
+	sort.Strings(dependents)
+	return dependents
 }
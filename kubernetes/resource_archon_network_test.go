@@ -2,11 +2,14 @@ package kubernetes
 
 import (
 	"fmt"
+	"reflect"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
-	"github.com/hashicorp/terraform/terraform"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	archon "kubeup.com/archon/pkg/clientset"
 	"kubeup.com/archon/pkg/cluster"
 )
@@ -19,12 +22,12 @@ func TestAccArchonNetwork_basic(t *testing.T) {
 		PreCheck:      func() { testAccPreCheck(t) },
 		IDRefreshName: "archon_network.test",
 		Providers:     testAccProviders,
-		CheckDestroy:  testAccCheckArchonNetworkDestroy,
+		CheckDestroy:  testAccCheckArchonDestroy("archon_network", testAccArchonNetworkClient),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccArchonNetworkConfig_basic(name),
 				Check: resource.ComposeAggregateTestCheckFunc(
-					testAccCheckArchonNetworkExists("archon_network.test", &conf),
+					testAccCheckArchonExists("archon_network.test", testAccArchonNetworkClient, &conf),
 					resource.TestCheckResourceAttr("archon_network.test", "metadata.0.annotations.%", "2"),
 					resource.TestCheckResourceAttr("archon_network.test", "metadata.0.annotations.TestAnnotationOne", "one"),
 					resource.TestCheckResourceAttr("archon_network.test", "metadata.0.annotations.TestAnnotationTwo", "two"),
@@ -48,7 +51,7 @@ func TestAccArchonNetwork_basic(t *testing.T) {
 			{
 				Config: testAccArchonNetworkConfig_modified(name),
 				Check: resource.ComposeAggregateTestCheckFunc(
-					testAccCheckArchonNetworkExists("archon_network.test", &conf),
+					testAccCheckArchonExists("archon_network.test", testAccArchonNetworkClient, &conf),
 					resource.TestCheckResourceAttr("archon_network.test", "metadata.0.annotations.%", "2"),
 					resource.TestCheckResourceAttr("archon_network.test", "metadata.0.annotations.TestAnnotationOne", "one"),
 					resource.TestCheckResourceAttr("archon_network.test", "metadata.0.annotations.Different", "1234"),
@@ -72,6 +75,10 @@ func TestAccArchonNetwork_basic(t *testing.T) {
 	})
 }
 
+func TestAccArchonNetwork_generatedName(t *testing.T) {
+	testAccArchonGeneratedNameTest(t, "archon_network", testAccArchonNetworkClient, testAccArchonNetworkConfig_generatedName)
+}
+
 func TestAccArchonNetwork_importBasic(t *testing.T) {
 	resourceName := "archon_network.test"
 	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
@@ -79,56 +86,112 @@ func TestAccArchonNetwork_importBasic(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
 		Providers:    testAccProviders,
-		CheckDestroy: testAccCheckArchonNetworkDestroy,
+		CheckDestroy: testAccCheckArchonDestroy("archon_network", testAccArchonNetworkClient),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccArchonNetworkConfig_basic(name),
 			},
 
 			{
-				ResourceName:      resourceName,
-				ImportState:       true,
-				ImportStateVerify: true,
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"force_destroy"},
 			},
 		},
 	})
 }
 
-func testAccCheckArchonNetworkDestroy(s *terraform.State) error {
-	conn := testAccProvider.Meta().(*archon.Clientset)
+func TestAccArchonNetwork_disappears(t *testing.T) {
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+	testAccArchonDisappearsTest(t, "archon_network", testAccArchonNetworkClient, testAccArchonNetworkConfig_basic(name))
+}
 
-	for _, rs := range s.RootModule().Resources {
-		if rs.Type != "archon_network" {
-			continue
-		}
-		namespace, name := idParts(rs.Primary.ID)
-		resp, err := conn.Archon().Networks(namespace).Get(name)
-		if err == nil {
-			if resp.Name == rs.Primary.ID {
-				return fmt.Errorf("Network still exists: %s", rs.Primary.ID)
-			}
-		}
-	}
+func TestAccArchonNetwork_inUse(t *testing.T) {
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
 
-	return nil
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckArchonDestroy("archon_network", testAccArchonNetworkClient),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccArchonNetworkConfig_inUse(name, false),
+			},
+			{
+				Config:      testAccArchonNetworkConfig_instanceOnly(name),
+				ExpectError: regexp.MustCompile(fmt.Sprintf(`is still in use by:\s+\* instance "%s"`, name)),
+			},
+			{
+				Config: testAccArchonNetworkConfig_inUse(name, true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("archon_network.test", "force_destroy", "true"),
+				),
+			},
+		},
+	})
 }
 
-func testAccCheckArchonNetworkExists(n string, obj *cluster.Network) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		rs, ok := s.RootModule().Resources[n]
-		if !ok {
-			return fmt.Errorf("Not found: %s", n)
+func TestNetworkDependents(t *testing.T) {
+	isController := true
+	group := func(name, network string) cluster.InstanceGroup {
+		ig := cluster.InstanceGroup{}
+		ig.Name = name
+		ig.Spec.Template.Spec.NetworkName = network
+		return ig
+	}
+	instance := func(name, network, owner string) cluster.Instance {
+		i := cluster.Instance{}
+		i.Name = name
+		i.Spec.NetworkName = network
+		if owner != "" {
+			i.OwnerReferences = []metav1.OwnerReference{
+				{Kind: "InstanceGroup", Name: owner, Controller: &isController},
+			}
 		}
+		return i
+	}
+	deletingGroup := group("deleting", "test")
+	deletingGroup.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	deletingInstance := instance("deleting", "test", "")
+	deletingInstance.DeletionTimestamp = &metav1.Time{Time: time.Now()}
 
-		conn := testAccProvider.Meta().(*archon.Clientset)
-		namespace, name := idParts(rs.Primary.ID)
-		out, err := conn.Archon().Networks(namespace).Get(name)
-		if err != nil {
-			return err
-		}
+	groups := []cluster.InstanceGroup{
+		group("web", "test"),
+		group("db", "other"),
+		deletingGroup,
+	}
+	instances := []cluster.Instance{
+		instance("standalone", "test", ""),
+		instance("elsewhere", "other", ""),
+		instance("web-1", "test", "web"),
+		instance("db-1", "test", "db"),
+		instance("deleting-1", "test", "deleting"),
+		// Orphaned, its group no longer exists
+		instance("gone-1", "test", "gone"),
+		deletingInstance,
+	}
 
-		*obj = *out
-		return nil
+	expected := []string{
+		`instance "db-1"`,
+		`instance "gone-1"`,
+		`instance "standalone"`,
+		`instance group "web"`,
+	}
+	dependents := networkDependents("test", groups, instances)
+	if !reflect.DeepEqual(dependents, expected) {
+		t.Fatalf("Expected dependents %q, got %q", expected, dependents)
+	}
+}
+
+func testAccArchonNetworkClient(conn *archon.Clientset) testAccArchonClient {
+	return testAccArchonClient{
+		Get: func(namespace, name string) (interface{}, error) {
+			return conn.Archon().Networks(namespace).Get(name)
+		},
+		Delete: func(namespace, name string) error {
+			return conn.Archon().Networks(namespace).Delete(name)
+		},
 	}
 }
 
@@ -176,3 +239,58 @@ resource "archon_network" "test" {
 	}
 }`, name)
 }
+
+func testAccArchonNetworkConfig_generatedName(prefix string) string {
+	return fmt.Sprintf(`
+resource "archon_network" "test" {
+	metadata {
+		generate_name = "%s"
+	}
+	spec {
+		region = "first"
+		zone = "second"
+		subnet = "10.0.0.0/24"
+	}
+}`, prefix)
+}
+
+func testAccArchonNetworkConfig_inUse(name string, forceDestroy bool) string {
+	return fmt.Sprintf(`
+resource "archon_network" "test" {
+	metadata {
+		name = "%s"
+	}
+	spec {
+		region = "first"
+		zone = "second"
+		subnet = "10.0.0.0/24"
+	}
+	force_destroy = %t
+}
+
+resource "archon_instance" "test" {
+	metadata {
+		name = "%s"
+	}
+	spec {
+		image = "first"
+		os = "second"
+		network_name = "%s"
+	}
+	depends_on = ["archon_network.test"]
+}`, name, forceDestroy, name, name)
+}
+
+func testAccArchonNetworkConfig_instanceOnly(name string) string {
+	return fmt.Sprintf(`
+resource "archon_instance" "test" {
+	metadata {
+		name = "%s"
+	}
+	spec {
+		image = "first"
+		os = "second"
+		network_name = "%s"
+	}
+}`, name, name)
+}
@@ -0,0 +1,192 @@
+package kubernetes
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"k8s.io/apimachinery/pkg/api/errors"
+	pkgApi "k8s.io/apimachinery/pkg/types"
+	archon "kubeup.com/archon/pkg/clientset"
+	"kubeup.com/archon/pkg/cluster"
+)
+
+func resourceArchonReservedInstance() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceArchonReservedInstanceCreate,
+		Read:   resourceArchonReservedInstanceRead,
+		Update: resourceArchonReservedInstanceUpdate,
+		Delete: resourceArchonReservedInstanceDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"metadata": namespacedMetadataSchema("reserved_instance", true),
+			"spec": {
+				Type:        schema.TypeList,
+				Description: "Archon ReservedInstance spec",
+				Required:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"os": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"image": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"instance_type": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"network_name": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"hostname": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"instance_id": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"configs": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem:     configSpecSchema(),
+						},
+					},
+				},
+			},
+			"status": {
+				Type:        schema.TypeList,
+				Description: "Archon ReservedInstance status",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"phase": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"instance_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceArchonReservedInstanceCreate(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*archon.Clientset)
+
+	metadata := expandMetadata(d.Get("metadata").([]interface{}))
+	spec, err := expandReservedInstanceSpec(d.Get("spec").([]interface{}))
+	if err != nil {
+		return err
+	}
+	reservedInstance := cluster.ReservedInstance{
+		ObjectMeta: metadata,
+		Spec:       spec,
+	}
+	log.Printf("[INFO] Creating new reserved_instance: %s", metadata.Name)
+	out, err := conn.Archon().ReservedInstances(metadata.Namespace).Create(&reservedInstance)
+	if err != nil {
+		return err
+	}
+	log.Printf("[INFO] Submitted new reserved_instance: %s", out.Name)
+	d.SetId(buildId(out.ObjectMeta))
+
+	return resourceArchonReservedInstanceRead(d, meta)
+}
+
+func resourceArchonReservedInstanceRead(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*archon.Clientset)
+
+	namespace, name := idParts(d.Id())
+	log.Printf("[INFO] Reading reserved_instance %s", name)
+	reservedInstance, err := conn.Archon().ReservedInstances(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Printf("[WARN] ReservedInstance %s not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		log.Printf("[DEBUG] Received error: %#v", err)
+		return err
+	}
+	log.Printf("[INFO] Received reserved_instance: %s", reservedInstance.Name)
+	err = d.Set("metadata", flattenMetadata(reservedInstance.ObjectMeta))
+	if err != nil {
+		return err
+	}
+
+	flattened := flattenReservedInstanceSpec(reservedInstance.Spec)
+	flattenConfigSources(flattened, d.Get("spec").([]interface{}))
+	err = d.Set("spec", flattened)
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("status", flattenReservedInstanceStatus(reservedInstance.Status))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func resourceArchonReservedInstanceUpdate(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*archon.Clientset)
+
+	namespace, name := idParts(d.Id())
+
+	ops := patchMetadata("metadata.0.", "/metadata/", d)
+	if d.HasChange("spec") {
+		current, err := conn.Archon().ReservedInstances(namespace).Get(name)
+		if err != nil {
+			return err
+		}
+		diffOps, err := patchReservedInstanceSpec("spec.0.", "/spec/", d, current.Spec)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, diffOps...)
+	}
+	data, err := ops.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("Failed to marshal update operations: %s", err)
+	}
+
+	log.Printf("[INFO] Updating reserved_instance %s: %d operations", name, len(ops))
+	out, err := conn.Archon().ReservedInstances(namespace).Patch(name, pkgApi.JSONPatchType, data)
+	if err != nil {
+		return err
+	}
+	log.Printf("[INFO] Submitted updated reserved_instance: %s", out.Name)
+	d.SetId(buildId(out.ObjectMeta))
+
+	return resourceArchonReservedInstanceRead(d, meta)
+}
+
+func resourceArchonReservedInstanceDelete(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*archon.Clientset)
+
+	namespace, name := idParts(d.Id())
+	log.Printf("[INFO] Deleting reserved_instance: %#v", name)
+	err := conn.Archon().ReservedInstances(namespace).Delete(name)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] ReservedInstance %s deleted", name)
+
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,225 @@
+package kubernetes
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	archon "kubeup.com/archon/pkg/clientset"
+	"kubeup.com/archon/pkg/cluster"
+)
+
+func TestAccArchonReservedInstance_basic(t *testing.T) {
+	var conf cluster.ReservedInstance
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:      func() { testAccPreCheck(t) },
+		IDRefreshName: "archon_reserved_instance.test",
+		Providers:     testAccProviders,
+		CheckDestroy:  testAccCheckArchonDestroy("archon_reserved_instance", testAccArchonReservedInstanceClient),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccArchonReservedInstanceConfig_basic(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckArchonExists("archon_reserved_instance.test", testAccArchonReservedInstanceClient, &conf),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "metadata.0.annotations.%", "2"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "metadata.0.annotations.TestAnnotationOne", "one"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "metadata.0.annotations.TestAnnotationTwo", "two"),
+					testAccCheckMetaAnnotations(&conf.ObjectMeta, map[string]string{"TestAnnotationOne": "one", "TestAnnotationTwo": "two"}),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "metadata.0.labels.%", "3"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "metadata.0.labels.TestLabelOne", "one"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "metadata.0.labels.TestLabelTwo", "two"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "metadata.0.labels.TestLabelThree", "three"),
+					testAccCheckMetaLabels(&conf.ObjectMeta, map[string]string{"TestLabelOne": "one", "TestLabelTwo": "two", "TestLabelThree": "three"}),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "metadata.0.name", name),
+					resource.TestCheckResourceAttrSet("archon_reserved_instance.test", "metadata.0.generation"),
+					resource.TestCheckResourceAttrSet("archon_reserved_instance.test", "metadata.0.resource_version"),
+					resource.TestCheckResourceAttrSet("archon_reserved_instance.test", "metadata.0.self_link"),
+					resource.TestCheckResourceAttrSet("archon_reserved_instance.test", "metadata.0.uid"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.#", "1"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.os", "CoreOS"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.image", "first"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.instance_type", "small"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.hostname", "reserved-1"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.instance_id", "i-0123456789"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.configs.#", "1"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.configs.0.name", "kubelet"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.configs.0.data.%", "1"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.configs.0.data.cluster-dns", "10.3.0.10"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.configs.0.sensitive_data.%", "1"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.configs.0.sensitive_data.token", "abcdef.0123456789abcdef"),
+					testAccCheckReservedInstanceConfigData(&conf, 0, map[string]string{"cluster-dns": "10.3.0.10", "token": "abcdef.0123456789abcdef"}),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "status.#", "1"),
+				),
+			},
+			{
+				Config: testAccArchonReservedInstanceConfig_modified(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckArchonExists("archon_reserved_instance.test", testAccArchonReservedInstanceClient, &conf),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "metadata.0.annotations.%", "2"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "metadata.0.annotations.TestAnnotationOne", "one"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "metadata.0.annotations.Different", "1234"),
+					testAccCheckMetaAnnotations(&conf.ObjectMeta, map[string]string{"TestAnnotationOne": "one", "Different": "1234"}),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "metadata.0.labels.%", "2"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "metadata.0.labels.TestLabelOne", "one"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "metadata.0.labels.TestLabelThree", "three"),
+					testAccCheckMetaLabels(&conf.ObjectMeta, map[string]string{"TestLabelOne": "one", "TestLabelThree": "three"}),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "metadata.0.name", name),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.#", "1"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.os", "CoreOS"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.image", "second"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.instance_type", "large"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.hostname", "reserved-1"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.instance_id", "i-0123456789"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.configs.0.data.%", "2"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.configs.0.data.labels", "role=worker"),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "spec.0.configs.0.sensitive_data.token", "fedcba.9876543210fedcba"),
+					testAccCheckReservedInstanceConfigData(&conf, 0, map[string]string{"cluster-dns": "10.3.0.10", "labels": "role=worker", "token": "fedcba.9876543210fedcba"}),
+					resource.TestCheckResourceAttr("archon_reserved_instance.test", "status.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccArchonReservedInstance_generatedName(t *testing.T) {
+	testAccArchonGeneratedNameTest(t, "archon_reserved_instance", testAccArchonReservedInstanceClient, testAccArchonReservedInstanceConfig_generatedName)
+}
+
+func TestAccArchonReservedInstance_importBasic(t *testing.T) {
+	resourceName := "archon_reserved_instance.test"
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckArchonDestroy("archon_reserved_instance", testAccArchonReservedInstanceClient),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccArchonReservedInstanceConfig_basic(name),
+			},
+
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				// Imported configs can't tell sensitive_data from data
+				ImportStateVerifyIgnore: []string{"spec.0.configs.0.data", "spec.0.configs.0.sensitive_data"},
+			},
+		},
+	})
+}
+
+func TestAccArchonReservedInstance_disappears(t *testing.T) {
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+	testAccArchonDisappearsTest(t, "archon_reserved_instance", testAccArchonReservedInstanceClient, testAccArchonReservedInstanceConfig_basic(name))
+}
+
+func testAccCheckReservedInstanceConfigData(obj *cluster.ReservedInstance, i int, expected map[string]string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if len(obj.Spec.Configs) <= i {
+			return fmt.Errorf("Expected at least %d configs, got %d", i+1, len(obj.Spec.Configs))
+		}
+		if !reflect.DeepEqual(obj.Spec.Configs[i].Data, expected) {
+			return fmt.Errorf("Expected config data %#v, got %#v", expected, obj.Spec.Configs[i].Data)
+		}
+		return nil
+	}
+}
+
+func testAccArchonReservedInstanceClient(conn *archon.Clientset) testAccArchonClient {
+	return testAccArchonClient{
+		Get: func(namespace, name string) (interface{}, error) {
+			return conn.Archon().ReservedInstances(namespace).Get(name)
+		},
+		Delete: func(namespace, name string) error {
+			return conn.Archon().ReservedInstances(namespace).Delete(name)
+		},
+	}
+}
+
+func testAccArchonReservedInstanceConfig_basic(name string) string {
+	return fmt.Sprintf(`
+resource "archon_reserved_instance" "test" {
+	metadata {
+		annotations {
+			TestAnnotationOne = "one"
+			TestAnnotationTwo = "two"
+		}
+		labels {
+			TestLabelOne = "one"
+			TestLabelTwo = "two"
+			TestLabelThree = "three"
+		}
+		name = "%s"
+	}
+	spec {
+		os = "CoreOS"
+		image = "first"
+		instance_type = "small"
+		hostname = "reserved-1"
+		instance_id = "i-0123456789"
+		configs {
+			name = "kubelet"
+			data {
+				cluster-dns = "10.3.0.10"
+			}
+			sensitive_data {
+				token = "abcdef.0123456789abcdef"
+			}
+		}
+	}
+}`, name)
+}
+
+func testAccArchonReservedInstanceConfig_modified(name string) string {
+	return fmt.Sprintf(`
+resource "archon_reserved_instance" "test" {
+	metadata {
+		annotations {
+			TestAnnotationOne = "one"
+			Different = "1234"
+		}
+		labels {
+			TestLabelOne = "one"
+			TestLabelThree = "three"
+		}
+		name = "%s"
+	}
+	spec {
+		os = "CoreOS"
+		image = "second"
+		instance_type = "large"
+		hostname = "reserved-1"
+		instance_id = "i-0123456789"
+		configs {
+			name = "kubelet"
+			data {
+				cluster-dns = "10.3.0.10"
+				labels = "role=worker"
+			}
+			sensitive_data {
+				token = "fedcba.9876543210fedcba"
+			}
+		}
+	}
+}`, name)
+}
+
+func testAccArchonReservedInstanceConfig_generatedName(prefix string) string {
+	return fmt.Sprintf(`
+resource "archon_reserved_instance" "test" {
+	metadata {
+		generate_name = "%s"
+	}
+	spec {
+		os = "CoreOS"
+		image = "first"
+		instance_id = "i-0123456789"
+	}
+}`, prefix)
+}
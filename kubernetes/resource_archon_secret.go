@@ -0,0 +1,158 @@
+package kubernetes
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	pkgApi "k8s.io/apimachinery/pkg/types"
+	api "k8s.io/kubernetes/pkg/api/v1"
+	archon "kubeup.com/archon/pkg/clientset"
+)
+
+func resourceArchonSecret() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceArchonSecretCreate,
+		Read:   resourceArchonSecretRead,
+		Update: resourceArchonSecretUpdate,
+		Delete: resourceArchonSecretDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"metadata": namespacedMetadataSchema("secret", true),
+			"data": {
+				Type:        schema.TypeMap,
+				Description: "A map of the secret data.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"binary_data": {
+				Type:         schema.TypeMap,
+				Description:  "A map of base64 encoded secret data, for values which aren't valid strings.",
+				Optional:     true,
+				Sensitive:    true,
+				ValidateFunc: validateBase64EncodedMap,
+			},
+			"type": {
+				Type:         schema.TypeString,
+				Description:  "Type of secret",
+				Optional:     true,
+				ForceNew:     true,
+				Default:      string(api.SecretTypeOpaque),
+				ValidateFunc: validateSecretType,
+			},
+		},
+	}
+}
+
+func resourceArchonSecretCreate(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*archon.Clientset)
+
+	metadata := expandMetadata(d.Get("metadata").([]interface{}))
+	data, err := expandSecretData(d.Get("data").(map[string]interface{}), d.Get("binary_data").(map[string]interface{}))
+	if err != nil {
+		return err
+	}
+	secret := api.Secret{
+		ObjectMeta: metadata,
+		Data:       data,
+		Type:       api.SecretType(d.Get("type").(string)),
+	}
+	log.Printf("[INFO] Creating new secret: %s", metadata.Name)
+	out, err := conn.CoreV1().Secrets(metadata.Namespace).Create(&secret)
+	if err != nil {
+		return err
+	}
+	log.Printf("[INFO] Submitted new secret: %s", out.Name)
+	d.SetId(buildId(out.ObjectMeta))
+
+	return resourceArchonSecretRead(d, meta)
+}
+
+func resourceArchonSecretRead(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*archon.Clientset)
+
+	namespace, name := idParts(d.Id())
+	log.Printf("[INFO] Reading secret %s", name)
+	secret, err := conn.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Printf("[WARN] Secret %s not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		log.Printf("[DEBUG] Received error: %#v", err)
+		return err
+	}
+	// Don't log the secret, data would end up in the log
+	log.Printf("[INFO] Received secret: %s", secret.Name)
+	err = d.Set("metadata", flattenMetadata(secret.ObjectMeta))
+	if err != nil {
+		return err
+	}
+
+	data, binaryData := flattenSecretData(secret.Data, d.Get("binary_data").(map[string]interface{}))
+	err = d.Set("data", data)
+	if err != nil {
+		return err
+	}
+	err = d.Set("binary_data", binaryData)
+	if err != nil {
+		return err
+	}
+	err = d.Set("type", string(secret.Type))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func resourceArchonSecretUpdate(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*archon.Clientset)
+
+	namespace, name := idParts(d.Id())
+
+	ops := patchMetadata("metadata.0.", "/metadata/", d)
+	if d.HasChange("data") || d.HasChange("binary_data") {
+		diffOps, err := patchSecretData("", "/", d)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, diffOps...)
+	}
+	data, err := ops.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("Failed to marshal update operations: %s", err)
+	}
+
+	log.Printf("[INFO] Updating secret %s: %d operations", name, len(ops))
+	out, err := conn.CoreV1().Secrets(namespace).Patch(name, pkgApi.JSONPatchType, data)
+	if err != nil {
+		return err
+	}
+	log.Printf("[INFO] Submitted updated secret: %s", out.Name)
+	d.SetId(buildId(out.ObjectMeta))
+
+	return resourceArchonSecretRead(d, meta)
+}
+
+func resourceArchonSecretDelete(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*archon.Clientset)
+
+	namespace, name := idParts(d.Id())
+	log.Printf("[INFO] Deleting secret: %#v", name)
+	err := conn.CoreV1().Secrets(namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Secret %s deleted", name)
+
+	d.SetId("")
+	return nil
+}
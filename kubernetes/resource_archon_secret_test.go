@@ -0,0 +1,169 @@
+package kubernetes
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	api "k8s.io/kubernetes/pkg/api/v1"
+	archon "kubeup.com/archon/pkg/clientset"
+)
+
+func TestAccArchonSecret_basic(t *testing.T) {
+	var conf api.Secret
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:      func() { testAccPreCheck(t) },
+		IDRefreshName: "archon_secret.test",
+		Providers:     testAccProviders,
+		CheckDestroy:  testAccCheckArchonDestroy("archon_secret", testAccArchonSecretClient),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccArchonSecretConfig_basic(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckArchonExists("archon_secret.test", testAccArchonSecretClient, &conf),
+					resource.TestCheckResourceAttr("archon_secret.test", "metadata.0.annotations.%", "1"),
+					resource.TestCheckResourceAttr("archon_secret.test", "metadata.0.annotations.TestAnnotationOne", "one"),
+					resource.TestCheckResourceAttr("archon_secret.test", "metadata.0.labels.%", "1"),
+					resource.TestCheckResourceAttr("archon_secret.test", "metadata.0.labels.TestLabelOne", "one"),
+					resource.TestCheckResourceAttr("archon_secret.test", "metadata.0.name", name),
+					resource.TestCheckResourceAttrSet("archon_secret.test", "metadata.0.resource_version"),
+					resource.TestCheckResourceAttrSet("archon_secret.test", "metadata.0.uid"),
+					resource.TestCheckResourceAttr("archon_secret.test", "data.%", "2"),
+					resource.TestCheckResourceAttr("archon_secret.test", "data.one", "first"),
+					resource.TestCheckResourceAttr("archon_secret.test", "data.two", "second"),
+					resource.TestCheckResourceAttr("archon_secret.test", "binary_data.%", "0"),
+					resource.TestCheckResourceAttr("archon_secret.test", "type", "Opaque"),
+					testAccCheckSecretData(&conf, map[string]string{"one": "first", "two": "second"}),
+				),
+			},
+			{
+				Config: testAccArchonSecretConfig_modified(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckArchonExists("archon_secret.test", testAccArchonSecretClient, &conf),
+					resource.TestCheckResourceAttr("archon_secret.test", "metadata.0.labels.%", "1"),
+					resource.TestCheckResourceAttr("archon_secret.test", "metadata.0.labels.TestLabelOne", "different"),
+					resource.TestCheckResourceAttr("archon_secret.test", "data.%", "2"),
+					resource.TestCheckResourceAttr("archon_secret.test", "data.one", "first"),
+					resource.TestCheckResourceAttr("archon_secret.test", "data.three", "third"),
+					resource.TestCheckResourceAttr("archon_secret.test", "binary_data.%", "1"),
+					resource.TestCheckResourceAttr("archon_secret.test", "binary_data.raw", "AAEC/w=="),
+					testAccCheckSecretData(&conf, map[string]string{"one": "first", "three": "third", "raw": "\x00\x01\x02\xff"}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccArchonSecret_generatedName(t *testing.T) {
+	testAccArchonGeneratedNameTest(t, "archon_secret", testAccArchonSecretClient, testAccArchonSecretConfig_generatedName)
+}
+
+func TestAccArchonSecret_importBasic(t *testing.T) {
+	resourceName := "archon_secret.test"
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckArchonDestroy("archon_secret", testAccArchonSecretClient),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccArchonSecretConfig_basic(name),
+			},
+
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccArchonSecret_disappears(t *testing.T) {
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+	testAccArchonDisappearsTest(t, "archon_secret", testAccArchonSecretClient, testAccArchonSecretConfig_basic(name))
+}
+
+func testAccCheckSecretData(s *api.Secret, expected map[string]string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		if len(s.Data) != len(expected) {
+			return fmt.Errorf("Expected %d secret keys, got %d", len(expected), len(s.Data))
+		}
+		for k, v := range expected {
+			if string(s.Data[k]) != v {
+				return fmt.Errorf("Secret key %q doesn't match", k)
+			}
+		}
+		return nil
+	}
+}
+
+func testAccArchonSecretClient(conn *archon.Clientset) testAccArchonClient {
+	return testAccArchonClient{
+		Get: func(namespace, name string) (interface{}, error) {
+			return conn.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+		},
+		Delete: func(namespace, name string) error {
+			return conn.CoreV1().Secrets(namespace).Delete(name, &metav1.DeleteOptions{})
+		},
+	}
+}
+
+func testAccArchonSecretConfig_basic(name string) string {
+	return fmt.Sprintf(`
+resource "archon_secret" "test" {
+	metadata {
+		annotations {
+			TestAnnotationOne = "one"
+		}
+		labels {
+			TestLabelOne = "one"
+		}
+		name = "%s"
+	}
+	data {
+		one = "first"
+		two = "second"
+	}
+}`, name)
+}
+
+func testAccArchonSecretConfig_modified(name string) string {
+	return fmt.Sprintf(`
+resource "archon_secret" "test" {
+	metadata {
+		annotations {
+			TestAnnotationOne = "one"
+		}
+		labels {
+			TestLabelOne = "different"
+		}
+		name = "%s"
+	}
+	data {
+		one = "first"
+		three = "third"
+	}
+	binary_data {
+		raw = "AAEC/w=="
+	}
+}`, name)
+}
+
+func testAccArchonSecretConfig_generatedName(prefix string) string {
+	return fmt.Sprintf(`
+resource "archon_secret" "test" {
+	metadata {
+		generate_name = "%s"
+	}
+	data {
+		one = "first"
+	}
+}`, prefix)
+}
@@ -3,7 +3,9 @@ package kubernetes
 import (
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 	"k8s.io/apimachinery/pkg/api/errors"
 	pkgApi "k8s.io/apimachinery/pkg/types"
@@ -15,12 +17,14 @@ func resourceArchonUser() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceArchonUserCreate,
 		Read:   resourceArchonUserRead,
-		Exists: resourceArchonUserExists,
 		Update: resourceArchonUserUpdate,
 		Delete: resourceArchonUserDelete,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Delete: schema.DefaultTimeout(1 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"metadata": namespacedMetadataSchema("user", true),
@@ -86,6 +90,11 @@ func resourceArchonUserRead(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[INFO] Reading user %s", name)
 	user, err := conn.Archon().Users(namespace).Get(name)
 	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Printf("[WARN] User %s not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
 		log.Printf("[DEBUG] Received error: %#v", err)
 		return err
 	}
@@ -141,24 +150,24 @@ func resourceArchonUserDelete(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
+	err = resource.Retry(d.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
+		_, err := conn.Archon().Users(namespace).Get(name)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return resource.NonRetryableError(err)
+		}
+
+		e := fmt.Errorf("User %s still exists", name)
+		return resource.RetryableError(e)
+	})
+	if err != nil {
+		return err
+	}
+
 	log.Printf("[INFO] User %s deleted", name)
 
 	d.SetId("")
 	return nil
 }
-
-func resourceArchonUserExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	conn := meta.(*archon.Clientset)
-
-	namespace, name := idParts(d.Id())
-	log.Printf("[INFO] Checking user %s", name)
-	_, err := conn.Archon().Users(namespace).Get(name)
-	if err != nil {
-		if statusErr, ok := err.(*errors.StatusError); ok && statusErr.ErrStatus.Code == 404 {
-			return false, nil
-		}
-		log.Printf("[DEBUG] Received error: %#v", err)
-	}
-	log.Printf("[INFO] User %s exists", name)
-	return true, err
-}
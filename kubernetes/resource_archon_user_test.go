@@ -6,7 +6,6 @@ import (
 
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
-	"github.com/hashicorp/terraform/terraform"
 	archon "kubeup.com/archon/pkg/clientset"
 	"kubeup.com/archon/pkg/cluster"
 )
@@ -19,12 +18,12 @@ func TestAccArchonUser_basic(t *testing.T) {
 		PreCheck:      func() { testAccPreCheck(t) },
 		IDRefreshName: "archon_user.test",
 		Providers:     testAccProviders,
-		CheckDestroy:  testAccCheckArchonUserDestroy,
+		CheckDestroy:  testAccCheckArchonDestroy("archon_user", testAccArchonUserClient),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccArchonUserConfig_basic(name),
 				Check: resource.ComposeAggregateTestCheckFunc(
-					testAccCheckArchonUserExists("archon_user.test", &conf),
+					testAccCheckArchonExists("archon_user.test", testAccArchonUserClient, &conf),
 					resource.TestCheckResourceAttr("archon_user.test", "metadata.0.annotations.%", "2"),
 					resource.TestCheckResourceAttr("archon_user.test", "metadata.0.annotations.TestAnnotationOne", "one"),
 					resource.TestCheckResourceAttr("archon_user.test", "metadata.0.annotations.TestAnnotationTwo", "two"),
@@ -47,7 +46,7 @@ func TestAccArchonUser_basic(t *testing.T) {
 			{
 				Config: testAccArchonUserConfig_modified(name),
 				Check: resource.ComposeAggregateTestCheckFunc(
-					testAccCheckArchonUserExists("archon_user.test", &conf),
+					testAccCheckArchonExists("archon_user.test", testAccArchonUserClient, &conf),
 					resource.TestCheckResourceAttr("archon_user.test", "metadata.0.annotations.%", "2"),
 					resource.TestCheckResourceAttr("archon_user.test", "metadata.0.annotations.TestAnnotationOne", "one"),
 					resource.TestCheckResourceAttr("archon_user.test", "metadata.0.annotations.Different", "1234"),
@@ -70,6 +69,10 @@ func TestAccArchonUser_basic(t *testing.T) {
 	})
 }
 
+func TestAccArchonUser_generatedName(t *testing.T) {
+	testAccArchonGeneratedNameTest(t, "archon_user", testAccArchonUserClient, testAccArchonUserConfig_generatedName)
+}
+
 func TestAccArchonUser_importBasic(t *testing.T) {
 	resourceName := "archon_user.test"
 	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
@@ -77,7 +80,7 @@ func TestAccArchonUser_importBasic(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
 		Providers:    testAccProviders,
-		CheckDestroy: testAccCheckArchonUserDestroy,
+		CheckDestroy: testAccCheckArchonDestroy("archon_user", testAccArchonUserClient),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccArchonUserConfig_basic(name),
@@ -92,41 +95,19 @@ func TestAccArchonUser_importBasic(t *testing.T) {
 	})
 }
 
-func testAccCheckArchonUserDestroy(s *terraform.State) error {
-	conn := testAccProvider.Meta().(*archon.Clientset)
-
-	for _, rs := range s.RootModule().Resources {
-		if rs.Type != "archon_user" {
-			continue
-		}
-		namespace, name := idParts(rs.Primary.ID)
-		resp, err := conn.Archon().Users(namespace).Get(name)
-		if err == nil {
-			if resp.Name == rs.Primary.ID {
-				return fmt.Errorf("User still exists: %s", rs.Primary.ID)
-			}
-		}
-	}
-
-	return nil
+func TestAccArchonUser_disappears(t *testing.T) {
+	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum))
+	testAccArchonDisappearsTest(t, "archon_user", testAccArchonUserClient, testAccArchonUserConfig_basic(name))
 }
 
-func testAccCheckArchonUserExists(n string, obj *cluster.User) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		rs, ok := s.RootModule().Resources[n]
-		if !ok {
-			return fmt.Errorf("Not found: %s", n)
-		}
-
-		conn := testAccProvider.Meta().(*archon.Clientset)
-		namespace, name := idParts(rs.Primary.ID)
-		out, err := conn.Archon().Users(namespace).Get(name)
-		if err != nil {
-			return err
-		}
-
-		*obj = *out
-		return nil
+func testAccArchonUserClient(conn *archon.Clientset) testAccArchonClient {
+	return testAccArchonClient{
+		Get: func(namespace, name string) (interface{}, error) {
+			return conn.Archon().Users(namespace).Get(name)
+		},
+		Delete: func(namespace, name string) error {
+			return conn.Archon().Users(namespace).Delete(name)
+		},
 	}
 }
 
@@ -172,3 +153,16 @@ resource "archon_user" "test" {
 	}
 }`, name)
 }
+
+func testAccArchonUserConfig_generatedName(prefix string) string {
+	return fmt.Sprintf(`
+resource "archon_user" "test" {
+	metadata {
+		generate_name = "%s"
+	}
+	spec {
+		name = "first"
+		password_hash = "second"
+	}
+}`, prefix)
+}
@@ -23,9 +23,10 @@ func instanceSpecFields() map[string]*schema.Schema {
 			Computed: false,
 		},
 		"reclaim_policy": {
-			Type:     schema.TypeString,
-			Optional: true,
-			Computed: true,
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			ValidateFunc: validateReclaimPolicy,
 		},
 		"files": {
 			Type:     schema.TypeList,
@@ -37,20 +38,32 @@ func instanceSpecFields() map[string]*schema.Schema {
 						Optional: true,
 					},
 					"encoding": {
-						Type:     schema.TypeString,
-						Optional: true,
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validateFileEncoding,
 					},
 					"content": {
-						Type:     schema.TypeString,
-						Optional: true,
+						Type:             schema.TypeString,
+						Optional:         true,
+						DiffSuppressFunc: suppressFileContentHash,
+					},
+					"content_encoding": {
+						Type:        schema.TypeString,
+						Description: "Set to `auto` to send content gzipped and base64 encoded, keeping only its sha256 in state.",
+						Optional:    true,
+						// An explicit default, so removing the attribute from the
+						// config takes precedence over the state in suppressFileContentHash
+						Default:      "",
+						ValidateFunc: validateAttributeValueIsIn([]string{"auto"}),
 					},
 					"template": {
 						Type:     schema.TypeString,
 						Optional: true,
 					},
 					"owner": {
-						Type:     schema.TypeString,
-						Optional: true,
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validateFileOwner,
 					},
 					"user_id": {
 						Type:     schema.TypeInt,
@@ -65,12 +78,14 @@ func instanceSpecFields() map[string]*schema.Schema {
 						Optional: true,
 					},
 					"path": {
-						Type:     schema.TypeString,
-						Optional: true,
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validateAbsolutePath,
 					},
 					"raw_file_permissions": {
-						Type:     schema.TypeString,
-						Optional: true,
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validateFilePermissions,
 					},
 				},
 			},
@@ -83,19 +98,7 @@ func instanceSpecFields() map[string]*schema.Schema {
 		"configs": {
 			Type:     schema.TypeList,
 			Optional: true,
-			Elem: &schema.Resource{
-				Schema: map[string]*schema.Schema{
-					"name": {
-						Type:     schema.TypeString,
-						Required: true,
-					},
-					"data": {
-						Type:         schema.TypeMap,
-						Optional:     true,
-						ValidateFunc: validateAnnotations,
-					},
-				},
-			},
+			Elem:     configSpecSchema(),
 		},
 		"users": {
 			Type:     schema.TypeList,
@@ -125,3 +128,114 @@ func localObjectReferenceSchema() *schema.Resource {
 		},
 	}
 }
+
+func configSpecSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"data": {
+				Type:         schema.TypeMap,
+				Optional:     true,
+				ValidateFunc: validateAnnotations,
+			},
+			"sensitive_data": {
+				Type:         schema.TypeMap,
+				Description:  "Like data, but hidden from the plan output.",
+				Optional:     true,
+				Sensitive:    true,
+				ValidateFunc: validateAnnotations,
+			},
+			"data_from_file": {
+				Type:             schema.TypeMap,
+				Description:      "A map of keys to local file paths. Only a sha256 of the file contents is kept in state.",
+				Optional:         true,
+				ValidateFunc:     validateAnnotations,
+				DiffSuppressFunc: suppressConfigFileHash,
+			},
+		},
+	}
+}
+
+func instanceStatusFields() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"phase": {
+			Type:        schema.TypeString,
+			Description: "Current lifecycle phase of the instance.",
+			Computed:    true,
+		},
+		"private_ip": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"public_ip": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"instance_id": {
+			Type:        schema.TypeString,
+			Description: "ID of the instance assigned by the cloud provider.",
+			Computed:    true,
+		},
+		"creation_timestamp": {
+			Type:        schema.TypeString,
+			Description: "Time the cloud instance was created, in RFC3339 format.",
+			Computed:    true,
+		},
+		"conditions": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"type": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"status": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"reason": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"message": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func instanceSummaryFields() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"namespace": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"private_ip": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"public_ip": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"instance_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"phase": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+	}
+}
@@ -1,6 +1,8 @@
 package kubernetes
 
 import (
+	"time"
+
 	"github.com/hashicorp/terraform/helper/schema"
 	"k8s.io/kubernetes/pkg/api/v1"
 	"kubeup.com/archon/pkg/cluster"
@@ -12,7 +14,10 @@ func flattenInstanceGroupSpec(in cluster.InstanceGroupSpec) []interface{} {
 	att := make(map[string]interface{})
 	att["replicas"] = in.Replicas
 	if in.ProvisionPolicy != "" {
-		att["provision_policy"] = in.ProvisionPolicy
+		att["provision_policy"] = string(in.ProvisionPolicy)
+	}
+	if in.MinReadySeconds != 0 {
+		att["min_ready_seconds"] = int(in.MinReadySeconds)
 	}
 	att["selector"] = flattenLabelSelector(in.Selector)
 	att["reserved_instance_selector"] = flattenLabelSelector(in.ReservedInstanceSelector)
@@ -41,15 +46,39 @@ func flattenSecret(in v1.Secret) map[string]interface{} {
 	att := make(map[string]interface{})
 	att["metadata"] = flattenMetadata(in.ObjectMeta)
 	att["data"] = byteMapToStringMap(in.Data)
-	att["type"] = in.Type
+	att["type"] = string(in.Type)
+	return att
+}
+
+func flattenInstanceGroupStatus(in cluster.InstanceGroupStatus) []interface{} {
+	att := make(map[string]interface{})
+	att["replicas"] = int(in.Replicas)
+	att["fully_labeled_replicas"] = int(in.FullyLabeledReplicas)
+	att["ready_replicas"] = int(in.ReadyReplicas)
+	att["available_replicas"] = int(in.AvailableReplicas)
+	att["observed_generation"] = int(in.ObservedGeneration)
+	att["conditions"] = flattenInstanceGroupConditions(in.Conditions)
+	return []interface{}{att}
+}
+
+func flattenInstanceGroupConditions(in []cluster.InstanceGroupCondition) []interface{} {
+	att := make([]interface{}, len(in))
+	for i, v := range in {
+		m := map[string]interface{}{}
+		m["type"] = string(v.Type)
+		m["status"] = string(v.Status)
+		m["reason"] = v.Reason
+		m["message"] = v.Message
+		att[i] = m
+	}
 	return att
 }
 
 // Expanders
 
-func expandInstanceGroupSpec(l []interface{}) cluster.InstanceGroupSpec {
+func expandInstanceGroupSpec(l []interface{}) (cluster.InstanceGroupSpec, error) {
 	if len(l) == 0 || l[0] == nil {
-		return cluster.InstanceGroupSpec{}
+		return cluster.InstanceGroupSpec{}, nil
 	}
 	in := l[0].(map[string]interface{})
 	obj := cluster.InstanceGroupSpec{}
@@ -57,8 +86,11 @@ func expandInstanceGroupSpec(l []interface{}) cluster.InstanceGroupSpec {
 	if v, ok := in["replicas"].(int); ok {
 		obj.Replicas = int32(v)
 	}
-	if v, ok := in["provision_policy"].(cluster.InstanceGroupProvisionPolicy); ok {
-		obj.ProvisionPolicy = v
+	if v, ok := in["provision_policy"].(string); ok && v != "" {
+		obj.ProvisionPolicy = cluster.InstanceGroupProvisionPolicy(v)
+	}
+	if v, ok := in["min_ready_seconds"].(int); ok {
+		obj.MinReadySeconds = int32(v)
 	}
 	if v, ok := in["selector"].([]interface{}); ok {
 		obj.Selector = expandLabelSelector(v)
@@ -67,14 +99,18 @@ func expandInstanceGroupSpec(l []interface{}) cluster.InstanceGroupSpec {
 		obj.ReservedInstanceSelector = expandLabelSelector(v)
 	}
 	if v, ok := in["template"].([]interface{}); ok {
-		obj.Template = expandInstanceTemplateSpec(v)
+		template, err := expandInstanceTemplateSpec(v)
+		if err != nil {
+			return obj, err
+		}
+		obj.Template = template
 	}
-	return obj
+	return obj, nil
 }
 
-func expandInstanceTemplateSpec(l []interface{}) cluster.InstanceTemplateSpec {
+func expandInstanceTemplateSpec(l []interface{}) (cluster.InstanceTemplateSpec, error) {
 	if len(l) == 0 || l[0] == nil {
-		return cluster.InstanceTemplateSpec{}
+		return cluster.InstanceTemplateSpec{}, nil
 	}
 	in := l[0].(map[string]interface{})
 	obj := cluster.InstanceTemplateSpec{}
@@ -84,13 +120,49 @@ func expandInstanceTemplateSpec(l []interface{}) cluster.InstanceTemplateSpec {
 	}
 
 	if v, ok := in["spec"].([]interface{}); ok {
-		obj.Spec = expandInstanceSpec(v)
+		spec, err := expandInstanceSpec(v)
+		if err != nil {
+			return obj, err
+		}
+		obj.Spec = spec
 	}
 
 	if v, ok := in["secrets"].([]interface{}); ok {
 		obj.Secrets = expandSecrets(v)
 	}
 
+	return obj, nil
+}
+
+// rollingUpdateOptions configures how the provider replaces outdated
+// members of an instance group, see rollInstanceGroup
+type rollingUpdateOptions struct {
+	MaxUnavailable int32
+	MaxSurge       int32
+	BatchPause     time.Duration
+	WaitForReady   bool
+}
+
+func expandRollingUpdate(l []interface{}) *rollingUpdateOptions {
+	if len(l) == 0 || l[0] == nil {
+		return nil
+	}
+	in := l[0].(map[string]interface{})
+	obj := &rollingUpdateOptions{}
+
+	if v, ok := in["max_unavailable"].(int); ok {
+		obj.MaxUnavailable = int32(v)
+	}
+	if v, ok := in["max_surge"].(int); ok {
+		obj.MaxSurge = int32(v)
+	}
+	if v, ok := in["batch_pause"].(string); ok && v != "" {
+		// Already checked by validateDuration
+		obj.BatchPause, _ = time.ParseDuration(v)
+	}
+	if v, ok := in["wait_for_ready"].(bool); ok {
+		obj.WaitForReady = v
+	}
 	return obj
 }
 
@@ -107,8 +179,8 @@ func expandSecrets(in []interface{}) []v1.Secret {
 		if v, ok := p["data"].(map[string]interface{}); ok {
 			secrets[i].StringData = expandStringMap(v)
 		}
-		if v, ok := p["type"].(v1.SecretType); ok {
-			secrets[i].Type = v
+		if v, ok := p["type"].(string); ok && v != "" {
+			secrets[i].Type = v1.SecretType(v)
 		}
 	}
 	return secrets
@@ -116,7 +188,7 @@ func expandSecrets(in []interface{}) []v1.Secret {
 
 // Patch Ops
 
-func patchInstanceGroupSpec(keyPrefix, pathPrefix string, d *schema.ResourceData) PatchOperations {
+func patchInstanceGroupSpec(keyPrefix, pathPrefix string, d *schema.ResourceData, current cluster.InstanceGroupSpec) (PatchOperations, error) {
 	ops := make([]PatchOperation, 0, 0)
 	if d.HasChange(keyPrefix + "replicas") {
 		ops = append(ops, &ReplaceOperation{
@@ -124,5 +196,55 @@ func patchInstanceGroupSpec(keyPrefix, pathPrefix string, d *schema.ResourceData
 			Value: d.Get(keyPrefix + "replicas").(int),
 		})
 	}
-	return ops
+	if d.HasChange(keyPrefix + "min_ready_seconds") {
+		// minReadySeconds is omitted from the object when zero,
+		// "add" sets it either way
+		ops = append(ops, &AddOperation{
+			Path:  pathPrefix + "minReadySeconds",
+			Value: d.Get(keyPrefix + "min_ready_seconds").(int),
+		})
+	}
+	if d.HasChange(keyPrefix + "template") {
+		diffOps, err := patchInstanceTemplateSpec(keyPrefix+"template.0.", pathPrefix+"template/", d, current.Template)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, diffOps...)
+	}
+	return ops, nil
+}
+
+// patchInstanceTemplateSpec only affects instances created after the
+// update, existing members of the group are left as they are
+func patchInstanceTemplateSpec(keyPrefix, pathPrefix string, d *schema.ResourceData, current cluster.InstanceTemplateSpec) (PatchOperations, error) {
+	ops := patchMetadata(keyPrefix+"metadata.0.", pathPrefix+"metadata/", d)
+	if d.HasChange(keyPrefix + "spec") {
+		spec, err := expandInstanceSpec(d.Get(keyPrefix + "spec").([]interface{}))
+		if err != nil {
+			return nil, err
+		}
+		err = restoreFileContents(spec.Files, current.Spec.Files)
+		if err != nil {
+			return nil, err
+		}
+		err = restoreConfigFiles(d.Get(keyPrefix+"spec.0.configs").([]interface{}), spec.Configs, current.Spec.Configs)
+		if err != nil {
+			return nil, err
+		}
+		// Nested lists don't diff well into single operations,
+		// so the whole spec is replaced
+		ops = append(ops, &ReplaceOperation{
+			Path:  pathPrefix + "spec",
+			Value: spec,
+		})
+	}
+	if d.HasChange(keyPrefix + "secrets") {
+		// secrets is omitted from the object when empty,
+		// "add" sets it either way
+		ops = append(ops, &AddOperation{
+			Path:  pathPrefix + "secrets",
+			Value: expandSecrets(d.Get(keyPrefix + "secrets").([]interface{})),
+		})
+	}
+	return ops, nil
 }
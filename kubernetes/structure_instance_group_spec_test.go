@@ -0,0 +1,69 @@
+package kubernetes
+
+import (
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/api/v1"
+	"kubeup.com/archon/pkg/cluster"
+)
+
+func TestInstanceGroupSpecProvisionPolicy(t *testing.T) {
+	testCases := []struct {
+		In       string
+		Expected cluster.InstanceGroupProvisionPolicy
+	}{
+		{"", ""},
+		{"ReservedOnly", cluster.InstanceGroupProvisionReservedOnly},
+		{"DynamicOnly", cluster.InstanceGroupProvisionDynamicOnly},
+		{"ReservedFirst", cluster.InstanceGroupProvisionReservedFirst},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			spec, err := expandInstanceGroupSpec([]interface{}{
+				map[string]interface{}{"provision_policy": tc.In},
+			})
+			if err != nil {
+				t.Fatalf("Failed to expand spec: %s", err)
+			}
+			if spec.ProvisionPolicy != tc.Expected {
+				t.Fatalf("Expected provision policy %q, got %q", tc.Expected, spec.ProvisionPolicy)
+			}
+			if tc.In == "" {
+				return
+			}
+			spec.Selector = &metav1.LabelSelector{}
+			spec.ReservedInstanceSelector = &metav1.LabelSelector{}
+			out := flattenInstanceGroupSpec(spec)[0].(map[string]interface{})
+			if out["provision_policy"] != tc.In {
+				t.Fatalf("Expected flattened provision policy %q, got %#v", tc.In, out["provision_policy"])
+			}
+		})
+	}
+}
+
+func TestSecretsType(t *testing.T) {
+	testCases := []struct {
+		In       string
+		Expected v1.SecretType
+	}{
+		{"", ""},
+		{"Opaque", v1.SecretTypeOpaque},
+		{"kubernetes.io/tls", v1.SecretTypeTLS},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			secrets := expandSecrets([]interface{}{
+				map[string]interface{}{"type": tc.In},
+			})
+			if secrets[0].Type != tc.Expected {
+				t.Fatalf("Expected secret type %q, got %q", tc.Expected, secrets[0].Type)
+			}
+			out := flattenSecret(secrets[0])
+			if out["type"] != tc.In {
+				t.Fatalf("Expected flattened secret type %q, got %#v", tc.In, out["type"])
+			}
+		})
+	}
+}
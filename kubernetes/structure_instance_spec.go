@@ -1,6 +1,11 @@
 package kubernetes
 
 import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
 	"kubeup.com/archon/pkg/cluster"
 )
 
@@ -21,7 +26,7 @@ func flattenInstanceSpec(in cluster.InstanceSpec) []interface{} {
 		att["network_name"] = in.NetworkName
 	}
 	if in.ReclaimPolicy != "" {
-		att["reclaim_policy"] = in.ReclaimPolicy
+		att["reclaim_policy"] = string(in.ReclaimPolicy)
 	}
 	if len(in.Files) > 0 {
 		att["files"] = flattenFiles(in.Files)
@@ -70,6 +75,10 @@ func flattenFileSpec(in cluster.FileSpec) map[string]interface{} {
 		att["template"] = in.Template
 	}
 
+	if in.Owner != "" {
+		att["owner"] = in.Owner
+	}
+
 	if in.UserID != 0 {
 		att["user_id"] = in.UserID
 	}
@@ -112,18 +121,116 @@ func flattenConfigs(in []cluster.ConfigSpec) []interface{} {
 			m["name"] = v.Name
 		}
 		if len(v.Data) > 0 {
-			m["data"] = v.Data
+			data := make(map[string]interface{}, len(v.Data))
+			for k, s := range v.Data {
+				data[k] = s
+			}
+			m["data"] = data
 		}
 		att[i] = m
 	}
 	return att
 }
 
+// flattenConfigSources moves the keys of each config which came from
+// sensitive_data or data_from_file according to the prior state of the spec
+// out of data, so the flattened configs can be compared with the config.
+// Keys from files are replaced by the hash of their contents.
+func flattenConfigSources(spec, prior []interface{}) {
+	if len(spec) == 0 || spec[0] == nil || len(prior) == 0 || prior[0] == nil {
+		return
+	}
+	configs, _ := spec[0].(map[string]interface{})["configs"].([]interface{})
+	priorConfigs, _ := prior[0].(map[string]interface{})["configs"].([]interface{})
+
+	for i, c := range configs {
+		if i >= len(priorConfigs) || priorConfigs[i] == nil {
+			break
+		}
+		config := c.(map[string]interface{})
+		p := priorConfigs[i].(map[string]interface{})
+		if config["name"] != p["name"] {
+			continue
+		}
+		data, _ := config["data"].(map[string]interface{})
+
+		sensitive := make(map[string]interface{})
+		if keys, ok := p["sensitive_data"].(map[string]interface{}); ok {
+			for k := range keys {
+				if v, ok := data[k]; ok {
+					sensitive[k] = v
+					delete(data, k)
+				}
+			}
+		}
+		fromFile := make(map[string]interface{})
+		if paths, ok := p["data_from_file"].(map[string]interface{}); ok {
+			for k := range paths {
+				if v, ok := data[k]; ok {
+					fromFile[k] = fileContentHash(v.(string))
+					delete(data, k)
+				}
+			}
+		}
+
+		if len(sensitive) > 0 {
+			config["sensitive_data"] = sensitive
+		}
+		if len(fromFile) > 0 {
+			config["data_from_file"] = fromFile
+		}
+		if len(data) == 0 {
+			delete(config, "data")
+		}
+	}
+}
+
+func flattenInstanceStatus(in cluster.InstanceStatus) []interface{} {
+	att := make(map[string]interface{})
+	att["phase"] = string(in.Phase)
+	att["private_ip"] = in.PrivateIP
+	att["public_ip"] = in.PublicIP
+	att["instance_id"] = in.InstanceID
+	if !in.CreationTimestamp.IsZero() {
+		att["creation_timestamp"] = in.CreationTimestamp.UTC().Format(time.RFC3339)
+	}
+	att["conditions"] = flattenInstanceConditions(in.Conditions)
+	return []interface{}{att}
+}
+
+func flattenInstanceConditions(in []cluster.InstanceCondition) []interface{} {
+	att := make([]interface{}, len(in))
+	for i, v := range in {
+		m := map[string]interface{}{}
+		m["type"] = string(v.Type)
+		m["status"] = string(v.Status)
+		m["reason"] = v.Reason
+		m["message"] = v.Message
+		att[i] = m
+	}
+	return att
+}
+
+func flattenInstanceSummaries(in []cluster.Instance) []interface{} {
+	att := make([]interface{}, len(in))
+	for i, v := range in {
+		m := map[string]interface{}{}
+		m["name"] = v.Name
+		m["namespace"] = v.Namespace
+		m["private_ip"] = v.Status.PrivateIP
+		m["public_ip"] = v.Status.PublicIP
+		m["instance_id"] = v.Status.InstanceID
+		m["phase"] = string(v.Status.Phase)
+		att[i] = m
+	}
+	return att
+}
+
 // Expanders
 
-func expandInstanceSpec(l []interface{}) cluster.InstanceSpec {
+func expandInstanceSpec(l []interface{}) (cluster.InstanceSpec, error) {
 	if len(l) == 0 || l[0] == nil {
-		return cluster.InstanceSpec{}
+		return cluster.InstanceSpec{}, nil
 	}
 	in := l[0].(map[string]interface{})
 	obj := cluster.InstanceSpec{}
@@ -140,6 +247,9 @@ func expandInstanceSpec(l []interface{}) cluster.InstanceSpec {
 	if v, ok := in["network_name"].(string); ok {
 		obj.NetworkName = v
 	}
+	if v, ok := in["reclaim_policy"].(string); ok && v != "" {
+		obj.ReclaimPolicy = cluster.InstanceReclaimPolicy(v)
+	}
 	if v, ok := in["files"].([]interface{}); ok {
 		obj.Files = expandFiles(v)
 	}
@@ -147,7 +257,11 @@ func expandInstanceSpec(l []interface{}) cluster.InstanceSpec {
 		obj.Secrets = expandArchonLocalObjectReferenceArray(v)
 	}
 	if v, ok := in["configs"].([]interface{}); ok {
-		obj.Configs = expandConfigs(v)
+		configs, err := expandConfigs(v)
+		if err != nil {
+			return obj, err
+		}
+		obj.Configs = configs
 	}
 	if v, ok := in["users"].([]interface{}); ok {
 		obj.Users = expandArchonLocalObjectReferenceArray(v)
@@ -161,7 +275,7 @@ func expandInstanceSpec(l []interface{}) cluster.InstanceSpec {
 			obj.ReservedInstanceRef = &c[0]
 		}
 	}
-	return obj
+	return obj, nil
 }
 
 func expandFiles(in []interface{}) []cluster.FileSpec {
@@ -181,10 +295,10 @@ func expandFiles(in []interface{}) []cluster.FileSpec {
 			files[i].Content = v.(string)
 		}
 		if v, ok := p["template"]; ok {
-			files[i].Content = v.(string)
+			files[i].Template = v.(string)
 		}
 		if v, ok := p["owner"]; ok {
-			files[i].Content = v.(string)
+			files[i].Owner = v.(string)
 		}
 		if v, ok := p["user_id"]; ok {
 			files[i].UserID = v.(int)
@@ -199,27 +313,76 @@ func expandFiles(in []interface{}) []cluster.FileSpec {
 			files[i].Path = v.(string)
 		}
 		if v, ok := p["raw_file_permissions"]; ok {
-			files[i].Path = v.(string)
+			files[i].RawFilePermissions = v.(string)
+		}
+		if v, ok := p["content_encoding"]; ok && v.(string) == "auto" && files[i].Content != "" {
+			files[i].Encoding = autoFileEncoding
+			// Unchanged content is only known by its hash,
+			// see restoreFileContents
+			if !isFileContentHash(files[i].Content) {
+				files[i].Content = encodeFileContent(files[i].Content)
+			}
 		}
 	}
 	return files
 }
 
-func expandConfigs(in []interface{}) []cluster.ConfigSpec {
+// expandConfigs merges data, sensitive_data and the contents of
+// data_from_file into one map per config. Specs holding the result must not
+// be logged.
+func expandConfigs(in []interface{}) ([]cluster.ConfigSpec, error) {
 	if len(in) == 0 {
-		return []cluster.ConfigSpec{}
+		return []cluster.ConfigSpec{}, nil
 	}
 	configs := make([]cluster.ConfigSpec, len(in))
 	for i, c := range in {
 		p := c.(map[string]interface{})
-		if v, ok := p["name"]; ok {
-			configs[i].Name = v.(string)
+		if v, ok := p["name"].(string); ok {
+			configs[i].Name = v
 		}
-		if v, ok := p["data"]; ok {
-			configs[i].Data = v.(map[string]string)
+		v, _ := p["data"].(map[string]interface{})
+		data := expandStringMap(v)
+		v, _ = p["sensitive_data"].(map[string]interface{})
+		for k, s := range expandStringMap(v) {
+			if _, ok := data[k]; ok {
+				return nil, fmt.Errorf("configs.%d: %q is set more than once", i, k)
+			}
+			data[k] = s
 		}
+		v, _ = p["data_from_file"].(map[string]interface{})
+		for k, path := range expandStringMap(v) {
+			if _, ok := data[k]; ok {
+				return nil, fmt.Errorf("configs.%d: %q is set more than once", i, k)
+			}
+			if isFileContentHash(path) {
+				// Unchanged file only known by its hash,
+				// see restoreConfigFiles
+				data[k] = path
+				continue
+			}
+			content, err := readConfigFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("configs.%d.data_from_file.%s: %s", i, k, err)
+			}
+			data[k] = content
+		}
+		if len(data) > 0 {
+			configs[i].Data = data
+		}
+	}
+	return configs, nil
+}
+
+func readConfigFile(path string) (string, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return "", err
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
 	}
-	return configs
+	return string(content), nil
 }
 
 func expandArchonLocalObjectReferenceArray(in []interface{}) []cluster.LocalObjectReference {
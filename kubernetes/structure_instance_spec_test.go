@@ -0,0 +1,346 @@
+package kubernetes
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"kubeup.com/archon/pkg/cluster"
+)
+
+func TestInstanceSpecRoundTrip(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{
+			"os":           "CoreOS",
+			"network_name": "net",
+		},
+		{
+			"os":            "CoreOS",
+			"image":         "ami-0123456789",
+			"instance_type": "t2.small",
+			"network_name":  "net",
+			"hostname":      "web-1",
+		},
+		{
+			"os":           "CoreOS",
+			"network_name": "net",
+			"files": []interface{}{
+				map[string]interface{}{
+					"name":                 "/etc/motd",
+					"encoding":             "gzip+base64",
+					"content":              "H4sIAAAAAAAA/8pIzcnJBwQAAP//",
+					"owner":                "core:core",
+					"user_id":              500,
+					"group_id":             500,
+					"filesystem":           "root",
+					"path":                 "/etc/motd",
+					"raw_file_permissions": "0644",
+				},
+				map[string]interface{}{
+					"name":                 "/etc/hosts",
+					"template":             "{{ .Status.PrivateIP }} {{ .Spec.Hostname }}",
+					"path":                 "/etc/hosts",
+					"raw_file_permissions": "644",
+				},
+			},
+		},
+		{
+			"os":           "CoreOS",
+			"network_name": "net",
+			"secrets": []interface{}{
+				map[string]interface{}{"name": "first"},
+				map[string]interface{}{"name": "second"},
+			},
+			"users": []interface{}{
+				map[string]interface{}{"name": "core"},
+			},
+			"reserved_instance_ref": []interface{}{
+				map[string]interface{}{"name": "reserved-1"},
+			},
+		},
+		{
+			"os":             "CoreOS",
+			"network_name":   "net",
+			"reclaim_policy": "Delete",
+		},
+		{
+			"os":           "CoreOS",
+			"network_name": "net",
+			"configs": []interface{}{
+				map[string]interface{}{
+					"name": "kubelet",
+					"data": map[string]interface{}{
+						"cluster-dns": "10.3.0.10",
+						"labels":      "role=worker",
+					},
+				},
+				map[string]interface{}{
+					"name": "empty",
+				},
+			},
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			in := []interface{}{tc}
+			spec, err := expandInstanceSpec(in)
+			if err != nil {
+				t.Fatalf("Failed to expand %#v: %s", in, err)
+			}
+			out := flattenInstanceSpec(spec)
+			if !reflect.DeepEqual(out, in) {
+				t.Fatalf("Expected round trip of %#v, got %#v", in, out)
+			}
+		})
+	}
+}
+
+func TestExpandFiles(t *testing.T) {
+	in := []interface{}{
+		map[string]interface{}{
+			"name":                 "motd",
+			"encoding":             "base64",
+			"content":              "aGVsbG8=",
+			"template":             "{{ .Name }}",
+			"owner":                "core:core",
+			"user_id":              500,
+			"group_id":             501,
+			"filesystem":           "root",
+			"path":                 "/etc/motd",
+			"raw_file_permissions": "0644",
+		},
+	}
+	expected := []cluster.FileSpec{
+		{
+			Name:               "motd",
+			Encoding:           "base64",
+			Content:            "aGVsbG8=",
+			Template:           "{{ .Name }}",
+			Owner:              "core:core",
+			UserID:             500,
+			GroupID:            501,
+			Filesystem:         "root",
+			Path:               "/etc/motd",
+			RawFilePermissions: "0644",
+		},
+	}
+
+	out := expandFiles(in)
+	if !reflect.DeepEqual(out, expected) {
+		t.Fatalf("Expected %#v, got %#v", expected, out)
+	}
+}
+
+func TestExpandInstanceSpecReclaimPolicy(t *testing.T) {
+	testCases := []struct {
+		In       string
+		Expected cluster.InstanceReclaimPolicy
+	}{
+		{"", ""},
+		{"Recycle", cluster.InstanceReclaimRecycle},
+		{"Delete", cluster.InstanceReclaimDelete},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			spec, err := expandInstanceSpec([]interface{}{
+				map[string]interface{}{"reclaim_policy": tc.In},
+			})
+			if err != nil {
+				t.Fatalf("Failed to expand spec: %s", err)
+			}
+			if spec.ReclaimPolicy != tc.Expected {
+				t.Fatalf("Expected reclaim policy %q, got %q", tc.Expected, spec.ReclaimPolicy)
+			}
+		})
+	}
+}
+
+func TestConfigSources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-archon-configs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ca.pem")
+	err = ioutil.WriteFile(path, []byte("-----BEGIN CERTIFICATE-----\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := []interface{}{
+		map[string]interface{}{
+			"os":           "CoreOS",
+			"network_name": "net",
+			"configs": []interface{}{
+				map[string]interface{}{
+					"name": "bootstrap",
+					"data": map[string]interface{}{
+						"server": "https://10.0.0.1",
+					},
+					"sensitive_data": map[string]interface{}{
+						"token": "abcdef.0123456789abcdef",
+					},
+					"data_from_file": map[string]interface{}{
+						"ca": path,
+					},
+				},
+			},
+		},
+	}
+
+	spec, err := expandInstanceSpec(in)
+	if err != nil {
+		t.Fatalf("Failed to expand spec: %s", err)
+	}
+	expected := []cluster.ConfigSpec{
+		{
+			Name: "bootstrap",
+			Data: map[string]string{
+				"server": "https://10.0.0.1",
+				"token":  "abcdef.0123456789abcdef",
+				"ca":     "-----BEGIN CERTIFICATE-----\n",
+			},
+		},
+	}
+	if !reflect.DeepEqual(spec.Configs, expected) {
+		t.Fatalf("Expected %#v, got %#v", expected, spec.Configs)
+	}
+
+	out := flattenInstanceSpec(spec)
+	flattenConfigSources(out, in)
+	bootstrap := out[0].(map[string]interface{})["configs"].([]interface{})[0].(map[string]interface{})
+	hash := fileContentHash("-----BEGIN CERTIFICATE-----\n")
+	if !reflect.DeepEqual(bootstrap["data_from_file"], map[string]interface{}{"ca": hash}) {
+		t.Fatalf("Expected data_from_file to hold the hash %q, got %#v", hash, bootstrap["data_from_file"])
+	}
+	bootstrap["data_from_file"] = map[string]interface{}{"ca": path}
+	if !reflect.DeepEqual(out, in) {
+		t.Fatalf("Expected round trip of %#v, got %#v", in, out)
+	}
+
+	// Expanding the state again keeps the hash, which is resolved against
+	// the configs stored in Archon
+	bootstrap["data_from_file"] = map[string]interface{}{"ca": hash}
+	restored, err := expandInstanceSpec(out)
+	if err != nil {
+		t.Fatalf("Failed to expand spec: %s", err)
+	}
+	if restored.Configs[0].Data["ca"] != hash {
+		t.Fatalf("Expected hash to be kept, got %q", restored.Configs[0].Data["ca"])
+	}
+	raw := out[0].(map[string]interface{})["configs"].([]interface{})
+	err = restoreConfigFiles(raw, restored.Configs, spec.Configs)
+	if err != nil {
+		t.Fatalf("Failed to restore configs: %s", err)
+	}
+	if !reflect.DeepEqual(restored.Configs, expected) {
+		t.Fatalf("Expected %#v, got %#v", expected, restored.Configs)
+	}
+
+	changed := []cluster.ConfigSpec{
+		{Name: "bootstrap", Data: map[string]string{"ca": "changed"}},
+	}
+	restored, _ = expandInstanceSpec(out)
+	if err := restoreConfigFiles(raw, restored.Configs, changed); err == nil {
+		t.Fatal("Expected an error when the stored data doesn't match the hash")
+	}
+}
+
+func TestSuppressConfigFileHash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-archon-configs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "token")
+
+	r := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"configs": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     configSpecSchema(),
+			},
+		},
+	}
+	state := &terraform.InstanceState{
+		ID: "test",
+		Attributes: map[string]string{
+			"configs.#":                   "1",
+			"configs.0.name":              "bootstrap",
+			"configs.0.data_from_file.%":  "1",
+			"configs.0.data_from_file.ca": fileContentHash("first"),
+		},
+	}
+	c, err := config.NewRawConfig(map[string]interface{}{
+		"configs": []interface{}{
+			map[string]interface{}{
+				"name":           "bootstrap",
+				"data_from_file": map[string]interface{}{"ca": path},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Content string
+		Diff    bool
+	}{
+		{"first", false},
+		{"second", true},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			err := ioutil.WriteFile(path, []byte(tc.Content), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			diff, err := r.Diff(state, terraform.NewResourceConfig(c))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var attr *terraform.ResourceAttrDiff
+			if diff != nil {
+				attr = diff.Attributes["configs.0.data_from_file.ca"]
+			}
+			if (attr != nil) != tc.Diff {
+				t.Fatalf("Expected diff to be %t for %q, got %#v", tc.Diff, tc.Content, attr)
+			}
+		})
+	}
+}
+
+func TestExpandConfigsErrors(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{
+			"name":           "duplicate",
+			"data":           map[string]interface{}{"token": "a"},
+			"sensitive_data": map[string]interface{}{"token": "b"},
+		},
+		{
+			"name":           "duplicate",
+			"sensitive_data": map[string]interface{}{"token": "b"},
+			"data_from_file": map[string]interface{}{"token": "/dev/null"},
+		},
+		{
+			"name":           "missing",
+			"data_from_file": map[string]interface{}{"token": "/nonexistent/tf-archon-config"},
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			_, err := expandConfigs([]interface{}{tc})
+			if err == nil {
+				t.Fatalf("Expected an error for %#v", tc)
+			}
+		})
+	}
+}
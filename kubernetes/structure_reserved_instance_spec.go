@@ -0,0 +1,144 @@
+package kubernetes
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"kubeup.com/archon/pkg/cluster"
+)
+
+// Flatteners
+
+func flattenReservedInstanceSpec(in cluster.ReservedInstanceSpec) []interface{} {
+	att := make(map[string]interface{})
+	if in.OS != "" {
+		att["os"] = in.OS
+	}
+	if in.Image != "" {
+		att["image"] = in.Image
+	}
+	if in.InstanceType != "" {
+		att["instance_type"] = in.InstanceType
+	}
+	if in.NetworkName != "" {
+		att["network_name"] = in.NetworkName
+	}
+	if in.Hostname != "" {
+		att["hostname"] = in.Hostname
+	}
+	if in.InstanceID != "" {
+		att["instance_id"] = in.InstanceID
+	}
+	if len(in.Configs) > 0 {
+		att["configs"] = flattenConfigs(in.Configs)
+	}
+	return []interface{}{att}
+}
+
+func flattenReservedInstanceStatus(in cluster.ReservedInstanceStatus) []interface{} {
+	att := make(map[string]interface{})
+	att["phase"] = string(in.Phase)
+	att["instance_name"] = in.InstanceName
+	return []interface{}{att}
+}
+
+// Expanders
+
+func expandReservedInstanceSpec(l []interface{}) (cluster.ReservedInstanceSpec, error) {
+	if len(l) == 0 || l[0] == nil {
+		return cluster.ReservedInstanceSpec{}, nil
+	}
+	in := l[0].(map[string]interface{})
+	obj := cluster.ReservedInstanceSpec{}
+
+	if v, ok := in["os"].(string); ok {
+		obj.OS = v
+	}
+	if v, ok := in["image"].(string); ok {
+		obj.Image = v
+	}
+	if v, ok := in["instance_type"].(string); ok {
+		obj.InstanceType = v
+	}
+	if v, ok := in["network_name"].(string); ok {
+		obj.NetworkName = v
+	}
+	if v, ok := in["hostname"].(string); ok {
+		obj.Hostname = v
+	}
+	if v, ok := in["instance_id"].(string); ok {
+		obj.InstanceID = v
+	}
+	if v, ok := in["configs"].([]interface{}); ok {
+		configs, err := expandConfigs(v)
+		if err != nil {
+			return obj, err
+		}
+		obj.Configs = configs
+	}
+	return obj, nil
+}
+
+// Patch Ops
+
+func patchReservedInstanceSpec(keyPrefix, pathPrefix string, d *schema.ResourceData, current cluster.ReservedInstanceSpec) (PatchOperations, error) {
+	ops := make([]PatchOperation, 0, 0)
+	if d.HasChange(keyPrefix + "os") {
+		ops = append(ops, &ReplaceOperation{
+			Path:  pathPrefix + "os",
+			Value: d.Get(keyPrefix + "os").(string),
+		})
+	}
+	if d.HasChange(keyPrefix + "image") {
+		ops = append(ops, &ReplaceOperation{
+			Path:  pathPrefix + "image",
+			Value: d.Get(keyPrefix + "image").(string),
+		})
+	}
+	if d.HasChange(keyPrefix + "instance_type") {
+		ops = append(ops, &ReplaceOperation{
+			Path:  pathPrefix + "instanceType",
+			Value: d.Get(keyPrefix + "instance_type").(string),
+		})
+	}
+	if d.HasChange(keyPrefix + "network_name") {
+		ops = append(ops, &ReplaceOperation{
+			Path:  pathPrefix + "networkName",
+			Value: d.Get(keyPrefix + "network_name").(string),
+		})
+	}
+	if d.HasChange(keyPrefix + "hostname") {
+		ops = append(ops, &ReplaceOperation{
+			Path:  pathPrefix + "hostname",
+			Value: d.Get(keyPrefix + "hostname").(string),
+		})
+	}
+	if d.HasChange(keyPrefix + "instance_id") {
+		ops = append(ops, &ReplaceOperation{
+			Path:  pathPrefix + "instanceID",
+			Value: d.Get(keyPrefix + "instance_id").(string),
+		})
+	}
+	if d.HasChange(keyPrefix + "configs") {
+		v := d.Get(keyPrefix + "configs").([]interface{})
+		if len(v) == 0 {
+			ops = append(ops, &RemoveOperation{
+				Path: pathPrefix + "configs",
+			})
+		} else {
+			configs, err := expandConfigs(v)
+			if err != nil {
+				return nil, err
+			}
+			err = restoreConfigFiles(v, configs, current.Configs)
+			if err != nil {
+				return nil, err
+			}
+			// "add" replaces an existing member, and also works
+			// when configs was omitted from the stored object
+			ops = append(ops, &AddOperation{
+				Path:  pathPrefix + "configs",
+				Value: configs,
+			})
+		}
+	}
+	return ops, nil
+}
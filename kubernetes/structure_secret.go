@@ -0,0 +1,84 @@
+package kubernetes
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Flatteners
+
+// flattenSecretData splits the secret data into plain strings and base64
+// encoded values. Keys listed in binaryKeys are returned base64 encoded,
+// everything else as is.
+func flattenSecretData(in map[string][]byte, binaryKeys map[string]interface{}) (map[string]string, map[string]string) {
+	data := make(map[string]string)
+	binaryData := make(map[string]string)
+	for k, v := range in {
+		if _, ok := binaryKeys[k]; ok {
+			binaryData[k] = base64.StdEncoding.EncodeToString(v)
+			continue
+		}
+		data[k] = string(v)
+	}
+	return data, binaryData
+}
+
+// Expanders
+
+func expandSecretData(data, binaryData map[string]interface{}) (map[string][]byte, error) {
+	obj := make(map[string][]byte, len(data)+len(binaryData))
+	for k, v := range data {
+		obj[k] = []byte(v.(string))
+	}
+	for k, v := range binaryData {
+		if _, ok := obj[k]; ok {
+			return nil, fmt.Errorf("%q is set in both data and binary_data", k)
+		}
+		b, err := base64.StdEncoding.DecodeString(v.(string))
+		if err != nil {
+			return nil, fmt.Errorf("binary_data.%s is not valid base64: %s", k, err)
+		}
+		obj[k] = b
+	}
+	return obj, nil
+}
+
+// Patch Ops
+
+func patchSecretData(keyPrefix, pathPrefix string, d *schema.ResourceData) (PatchOperations, error) {
+	oldData, newData := d.GetChange(keyPrefix + "data")
+	oldBinary, newBinary := d.GetChange(keyPrefix + "binary_data")
+
+	oldV, err := expandSecretData(oldData.(map[string]interface{}), oldBinary.(map[string]interface{}))
+	if err != nil {
+		return nil, err
+	}
+	newV, err := expandSecretData(newData.(map[string]interface{}), newBinary.(map[string]interface{}))
+	if err != nil {
+		return nil, err
+	}
+
+	// data is omitted from the object when empty,
+	// so it can't be patched key by key
+	if len(oldV) == 0 {
+		return PatchOperations{
+			&AddOperation{
+				Path:  pathPrefix + "data",
+				Value: newV,
+			},
+		}, nil
+	}
+
+	// []byte values are base64 encoded in JSON
+	return diffStringMap(pathPrefix+"data", base64StringMap(oldV), base64StringMap(newV)), nil
+}
+
+func base64StringMap(m map[string][]byte) map[string]interface{} {
+	result := make(map[string]interface{})
+	for k, v := range m {
+		result[k] = base64.StdEncoding.EncodeToString(v)
+	}
+	return result
+}
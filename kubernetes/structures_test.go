@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -30,3 +31,71 @@ func TestIsInternalKey(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretDataRoundTrip(t *testing.T) {
+	testCases := []struct {
+		Data       map[string]interface{}
+		BinaryData map[string]interface{}
+		Expected   map[string][]byte
+	}{
+		{
+			map[string]interface{}{},
+			map[string]interface{}{},
+			map[string][]byte{},
+		},
+		{
+			map[string]interface{}{"one": "first"},
+			map[string]interface{}{},
+			map[string][]byte{"one": []byte("first")},
+		},
+		{
+			map[string]interface{}{"one": "first"},
+			map[string]interface{}{"raw": "AAEC/w=="},
+			map[string][]byte{"one": []byte("first"), "raw": {0, 1, 2, 255}},
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			out, err := expandSecretData(tc.Data, tc.BinaryData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(out, tc.Expected) {
+				t.Fatalf("Expected %#v, got %#v", tc.Expected, out)
+			}
+
+			data, binaryData := flattenSecretData(out, tc.BinaryData)
+			if len(data) != len(tc.Data) || len(binaryData) != len(tc.BinaryData) {
+				t.Fatalf("Expected %#v and %#v, got %#v and %#v", tc.Data, tc.BinaryData, data, binaryData)
+			}
+			for k, v := range data {
+				if tc.Data[k] != v {
+					t.Fatalf("Expected data.%s to be %q, got %q", k, tc.Data[k], v)
+				}
+			}
+			for k, v := range binaryData {
+				if tc.BinaryData[k] != v {
+					t.Fatalf("Expected binary_data.%s to be %q, got %q", k, tc.BinaryData[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestExpandSecretDataErrors(t *testing.T) {
+	testCases := []struct {
+		Data       map[string]interface{}
+		BinaryData map[string]interface{}
+	}{
+		{map[string]interface{}{"one": "first"}, map[string]interface{}{"one": "AAEC"}},
+		{map[string]interface{}{}, map[string]interface{}{"raw": "not base64"}},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			_, err := expandSecretData(tc.Data, tc.BinaryData)
+			if err == nil {
+				t.Fatalf("Expected %#v and %#v to be invalid", tc.Data, tc.BinaryData)
+			}
+		})
+	}
+}
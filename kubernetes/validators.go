@@ -1,15 +1,21 @@
 package kubernetes
 
 import (
+	"encoding/base64"
 	"fmt"
+	"path"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 
 	"k8s.io/apimachinery/pkg/api/resource"
 	apiValidation "k8s.io/apimachinery/pkg/api/validation"
 	utilValidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/kubernetes/pkg/api/v1"
+	"kubeup.com/archon/pkg/cluster"
 )
 
 func validateAnnotations(value interface{}, key string) (ws []string, es []error) {
@@ -138,6 +144,127 @@ func validatePositiveInteger(value interface{}, key string) (ws []string, es []e
 	return
 }
 
+func validateNonNegativeInteger(value interface{}, key string) (ws []string, es []error) {
+	v := value.(int)
+	if v < 0 {
+		es = append(es, fmt.Errorf("%s must be greater than or equal to 0", key))
+	}
+	return
+}
+
+func validateDuration(value interface{}, key string) (ws []string, es []error) {
+	v := value.(string)
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		es = append(es, fmt.Errorf("%s (%q) is not a valid duration: %s", key, v, err))
+	} else if d < 0 {
+		es = append(es, fmt.Errorf("%s (%q) must not be negative", key, v))
+	}
+	return
+}
+
+func validateBase64EncodedMap(value interface{}, key string) (ws []string, es []error) {
+	m := value.(map[string]interface{})
+	for k, v := range m {
+		if _, err := base64.StdEncoding.DecodeString(v.(string)); err != nil {
+			es = append(es, fmt.Errorf("%s.%s must be base64 encoded: %s", key, k, err))
+		}
+	}
+	return
+}
+
+// Mirrors the valid: tags of cluster.FileSpec
+var (
+	fileEncodingRegexp    = regexp.MustCompile(`^(base64|b64|gz|gzip|gz\+base64|gzip\+base64|gz\+b64|gzip\+b64)$`)
+	filePermissionsRegexp = regexp.MustCompile(`^0?[0-7]{3,4}$`)
+	fileOwnerRegexp       = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*(:[A-Za-z0-9_][A-Za-z0-9_.-]*)?$`)
+)
+
+func validateFileEncoding(value interface{}, key string) (ws []string, es []error) {
+	v := value.(string)
+	if !fileEncodingRegexp.MatchString(v) {
+		es = append(es, fmt.Errorf("%s (%q) must be one of base64, b64, gz, gzip or a combination like gzip+base64", key, v))
+	}
+	return
+}
+
+func validateFilePermissions(value interface{}, key string) (ws []string, es []error) {
+	v := value.(string)
+	if !filePermissionsRegexp.MatchString(v) {
+		es = append(es, fmt.Errorf("%s (%q) expects octal notation, e.g. 0644", key, v))
+	}
+	return
+}
+
+func validateAbsolutePath(value interface{}, key string) (ws []string, es []error) {
+	v := value.(string)
+	if !path.IsAbs(v) {
+		es = append(es, fmt.Errorf("%s (%q) must be an absolute path", key, v))
+	}
+	return
+}
+
+func validateFileOwner(value interface{}, key string) (ws []string, es []error) {
+	v := value.(string)
+	if !fileOwnerRegexp.MatchString(v) {
+		es = append(es, fmt.Errorf("%s (%q) must be in the form user or user:group", key, v))
+	}
+	return
+}
+
+// validateFileSpecs checks rules spanning several fields of a file,
+// which can't be expressed in the schema of list elements
+func validateFileSpecs(keyPrefix string, files []interface{}) error {
+	for i, f := range files {
+		if f == nil {
+			continue
+		}
+		file := f.(map[string]interface{})
+		content, _ := file["content"].(string)
+		template, _ := file["template"].(string)
+		encoding, _ := file["encoding"].(string)
+		contentEncoding, _ := file["content_encoding"].(string)
+
+		if content != "" && template != "" {
+			return fmt.Errorf("%s%d: content and template are mutually exclusive", keyPrefix, i)
+		}
+		if contentEncoding == "auto" && encoding != "" {
+			return fmt.Errorf("%s%d: encoding can't be set with content_encoding = \"auto\"", keyPrefix, i)
+		}
+		if contentEncoding == "auto" && template != "" {
+			return fmt.Errorf("%s%d: content_encoding = \"auto\" only applies to content", keyPrefix, i)
+		}
+	}
+	return nil
+}
+
+func validateProvisionPolicy(value interface{}, key string) (ws []string, es []error) {
+	return validateAttributeValueIsIn([]string{
+		string(cluster.InstanceGroupProvisionReservedOnly),
+		string(cluster.InstanceGroupProvisionDynamicOnly),
+		string(cluster.InstanceGroupProvisionReservedFirst),
+	})(value, key)
+}
+
+func validateReclaimPolicy(value interface{}, key string) (ws []string, es []error) {
+	return validateAttributeValueIsIn([]string{
+		string(cluster.InstanceReclaimRecycle),
+		string(cluster.InstanceReclaimDelete),
+	})(value, key)
+}
+
+func validateSecretType(value interface{}, key string) (ws []string, es []error) {
+	return validateAttributeValueIsIn([]string{
+		string(v1.SecretTypeOpaque),
+		string(v1.SecretTypeServiceAccountToken),
+		string(v1.SecretTypeDockercfg),
+		string(v1.SecretTypeDockerConfigJson),
+		string(v1.SecretTypeBasicAuth),
+		string(v1.SecretTypeSSHAuth),
+		string(v1.SecretTypeTLS),
+	})(value, key)
+}
+
 func validateDNSPolicy(value interface{}, key string) (ws []string, es []error) {
 	v := value.(string)
 	if v != "ClusterFirst" && v != "Default" {
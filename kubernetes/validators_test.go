@@ -1,6 +1,7 @@
 package kubernetes
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -25,3 +26,156 @@ func TestValidateModeBits(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateDuration(t *testing.T) {
+	validCases := []string{
+		"0s", "30s", "1m30s", "2h",
+	}
+	for _, v := range validCases {
+		_, es := validateDuration(v, "batch_pause")
+		if len(es) > 0 {
+			t.Fatalf("Expected %q to be valid: %#v", v, es)
+		}
+	}
+
+	invalidCases := []string{
+		"", "30", "-1s", "soon",
+	}
+	for _, v := range invalidCases {
+		_, es := validateDuration(v, "batch_pause")
+		if len(es) == 0 {
+			t.Fatalf("Expected %q to be invalid", v)
+		}
+	}
+}
+
+func TestValidateFileFields(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Validate func(interface{}, string) ([]string, []error)
+		Valid    []string
+		Invalid  []string
+	}{
+		{
+			Name:     "encoding",
+			Validate: validateFileEncoding,
+			Valid:    []string{"base64", "b64", "gz", "gzip", "gz+base64", "gzip+base64", "gz+b64", "gzip+b64"},
+			Invalid:  []string{"", "BASE64", "plain", "base64+gzip", "gzip+base64 "},
+		},
+		{
+			Name:     "raw_file_permissions",
+			Validate: validateFilePermissions,
+			Valid:    []string{"644", "0644", "0755", "1777", "01777"},
+			Invalid:  []string{"", "0x644", "888", "64", "u+rw", "001777"},
+		},
+		{
+			Name:     "path",
+			Validate: validateAbsolutePath,
+			Valid:    []string{"/", "/etc/motd", "/opt/bin/run.sh"},
+			Invalid:  []string{"", "etc/motd", "./motd", "~/motd"},
+		},
+		{
+			Name:     "owner",
+			Validate: validateFileOwner,
+			Valid:    []string{"core", "core:core", "root:wheel", "500:500", "www-data:www-data"},
+			Invalid:  []string{"", ":core", "core:", "core:core:core", "core group", "-core"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			key := "spec.0.files.1." + tc.Name
+			for _, v := range tc.Valid {
+				_, es := tc.Validate(v, key)
+				if len(es) > 0 {
+					t.Fatalf("Expected %q to be valid: %#v", v, es)
+				}
+			}
+			for _, v := range tc.Invalid {
+				_, es := tc.Validate(v, key)
+				if len(es) == 0 {
+					t.Fatalf("Expected %q to be invalid", v)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateFileSpecs(t *testing.T) {
+	valid := []interface{}{
+		map[string]interface{}{"name": "a", "content": "hello"},
+		map[string]interface{}{"name": "b", "template": "{{ .Name }}"},
+		map[string]interface{}{"name": "c"},
+		map[string]interface{}{"name": "d", "content": "hello", "content_encoding": "auto"},
+	}
+	if err := validateFileSpecs("spec.0.files.", valid); err != nil {
+		t.Fatalf("Expected files to be valid: %s", err)
+	}
+
+	invalidCases := []struct {
+		File     map[string]interface{}
+		Expected string
+	}{
+		{
+			map[string]interface{}{"name": "e", "content": "hello", "template": "{{ .Name }}"},
+			"spec.0.files.4: content and template are mutually exclusive",
+		},
+		{
+			map[string]interface{}{"name": "e", "content": "aGVsbG8=", "encoding": "base64", "content_encoding": "auto"},
+			"spec.0.files.4: encoding can't be set with content_encoding = \"auto\"",
+		},
+		{
+			map[string]interface{}{"name": "e", "template": "{{ .Name }}", "content_encoding": "auto"},
+			"spec.0.files.4: content_encoding = \"auto\" only applies to content",
+		},
+	}
+	for i, tc := range invalidCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			files := append(append([]interface{}{}, valid...), tc.File)
+			err := validateFileSpecs("spec.0.files.", files)
+			if err == nil {
+				t.Fatalf("Expected files to be invalid")
+			}
+			if err.Error() != tc.Expected {
+				t.Fatalf("Expected error %q, got %q", tc.Expected, err)
+			}
+		})
+	}
+}
+
+func TestValidateEnums(t *testing.T) {
+	testCases := []struct {
+		Validate func(interface{}, string) ([]string, []error)
+		Valid    []string
+		Invalid  []string
+	}{
+		{
+			validateProvisionPolicy,
+			[]string{"ReservedOnly", "DynamicOnly", "ReservedFirst"},
+			[]string{"", "dynamiconly", "Dynamic"},
+		},
+		{
+			validateReclaimPolicy,
+			[]string{"Recycle", "Delete"},
+			[]string{"", "delete", "Retain"},
+		},
+		{
+			validateSecretType,
+			[]string{"Opaque", "kubernetes.io/tls", "kubernetes.io/dockerconfigjson"},
+			[]string{"", "opaque", "kubernetes.io/unknown"},
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			for _, v := range tc.Valid {
+				if _, es := tc.Validate(v, "field"); len(es) > 0 {
+					t.Fatalf("Expected %q to be valid: %v", v, es)
+				}
+			}
+			for _, v := range tc.Invalid {
+				if _, es := tc.Validate(v, "field"); len(es) == 0 {
+					t.Fatalf("Expected %q to be invalid", v)
+				}
+			}
+		})
+	}
+}